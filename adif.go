@@ -0,0 +1,109 @@
+package cabrillo
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var adifBands = map[CategoryBand]string{
+	Band160m:  "160m",
+	Band80m:   "80m",
+	Band40m:   "40m",
+	Band20m:   "20m",
+	Band15m:   "15m",
+	Band10m:   "10m",
+	Band6m:    "6m",
+	Band4m:    "4m",
+	Band2m:    "2m",
+	Band222:   "1.25m",
+	Band432:   "70cm",
+	Band902:   "33cm",
+	Band1_2G:  "23cm",
+	Band2_3G:  "13cm",
+	Band3_4G:  "9cm",
+	Band5_6G:  "6cm",
+	Band10G:   "3cm",
+	Band24G:   "1.25cm",
+	Band47G:   "6mm",
+	Band75G:   "4mm",
+	Band122G:  "2.5mm",
+	Band134G:  "2mm",
+	Band241G:  "1mm",
+	BandLight: "submm",
+}
+
+var adifModes = map[QSOMode]string{
+	QSOModeCW:    "CW",
+	QSOModePhone: "SSB",
+	QSOModeFM:    "FM",
+	QSOModeRTTY:  "RTTY",
+	// ADIF has no generic digital mode, most digital contest QSOs are made in one of the MFSK modes
+	QSOModeDigi: "MFSK",
+}
+
+// WriteADIF writes the QSO data of the given log in the ADIF format. The exchange is written
+// into the STX_STRING and SRX_STRING fields.
+func WriteADIF(w io.Writer, l *Log) error {
+	_, err := fmt.Fprintf(w, "Cabrillo export of %s\n", l.Callsign)
+	if err != nil {
+		return err
+	}
+	err = writeADIFFields(w,
+		adifField{"ADIF_VER", "3.1.4"},
+		adifField{"PROGRAMID", "ftl/cabrillo"},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, "<EOH>")
+	if err != nil {
+		return err
+	}
+
+	for _, qso := range l.QSOData {
+		fields := []adifField{
+			{"QSO_DATE", qso.Timestamp.UTC().Format("20060102")},
+			{"TIME_ON", qso.Timestamp.UTC().Format("1504")},
+			{"CALL", qso.Received.Call.String()},
+			{"BAND", adifBands[qso.Frequency.ToBand()]},
+			{"MODE", adifModes[qso.Mode]},
+			{"STATION_CALLSIGN", qso.Sent.Call.String()},
+			{"CONTEST_ID", string(l.Contest)},
+			{"STX_STRING", strings.Join(qso.Sent.Exchange, " ")},
+			{"SRX_STRING", strings.Join(qso.Received.Exchange, " ")},
+		}
+		if qso.Frequency.IsFrequency() {
+			mhz := strconv.FormatFloat(float64(qso.Frequency.ToKilohertz())/1000, 'f', 3, 64)
+			fields = append(fields, adifField{"FREQ", mhz})
+		}
+		err = writeADIFFields(w, fields...)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, "<EOR>")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type adifField struct {
+	name  string
+	value string
+}
+
+func writeADIFFields(w io.Writer, fields ...adifField) error {
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		_, err := fmt.Fprintf(w, "<%s:%d>%s ", field.name, len(field.value), field.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cabrillo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteADIF(t *testing.T) {
+	log := NewLog()
+	log.Callsign = callsign.MustParse("DL1ABC")
+	log.Contest = "CQ-WW-CW"
+	qso := scoringTestQSO("14025", QSOModeCW, "W1AW", "5")
+	qso.Timestamp = time.Date(2024, time.October, 26, 7, 11, 0, 0, time.UTC)
+	log.QSOData = []QSO{qso}
+	buffer := &bytes.Buffer{}
+
+	err := WriteADIF(buffer, log)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Cabrillo export of DL1ABC\n"+
+		"<ADIF_VER:5>3.1.4 <PROGRAMID:12>ftl/cabrillo <EOH>\n"+
+		"<QSO_DATE:8>20241026 <TIME_ON:4>0711 <CALL:4>W1AW <BAND:3>20m <MODE:2>CW <STATION_CALLSIGN:6>DL1ABC <CONTEST_ID:8>CQ-WW-CW <STX_STRING:6>599 14 <SRX_STRING:5>599 5 <FREQ:6>14.025 <EOR>\n",
+		buffer.String())
+}
+
+func TestWriteADIF_BandAndMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency QSOFrequency
+		mode      QSOMode
+		expected  string
+	}{
+		{name: "CW", frequency: "7025", mode: QSOModeCW, expected: "<BAND:3>40m <MODE:2>CW "},
+		{name: "phone", frequency: "3750", mode: QSOModePhone, expected: "<BAND:3>80m <MODE:3>SSB "},
+		{name: "FM", frequency: Frequency144MHz, mode: QSOModeFM, expected: "<BAND:2>2m <MODE:2>FM "},
+		{name: "RTTY", frequency: "21080", mode: QSOModeRTTY, expected: "<BAND:3>15m <MODE:4>RTTY "},
+		{name: "digital", frequency: "28074", mode: QSOModeDigi, expected: "<BAND:3>10m <MODE:4>MFSK "},
+		{name: "band designator", frequency: Frequency432MHz, mode: QSOModeCW, expected: "<BAND:4>70cm <MODE:2>CW "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := NewLog()
+			log.QSOData = []QSO{scoringTestQSO(tt.frequency, tt.mode, "W1AW", "5")}
+			buffer := &bytes.Buffer{}
+
+			err := WriteADIF(buffer, log)
+			require.NoError(t, err)
+
+			assert.Contains(t, buffer.String(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package cabrillo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BandChangeRules describe the restrictions for the band changes of each transmitter.
+type BandChangeRules struct {
+	// MinimumDwell is the time a transmitter must stay on a band before it may change to another band,
+	// e.g. 10 minutes for the 10-minute rule. 0 means no restriction.
+	MinimumDwell time.Duration
+	// MaxChangesPerHour is the maximum number of band changes of a transmitter within a clock hour.
+	// 0 means no restriction.
+	MaxChangesPerHour int
+}
+
+var (
+	TenMinuteRule       = BandChangeRules{MinimumDwell: 10 * time.Minute}
+	EightChangesPerHour = BandChangeRules{MaxChangesPerHour: 8}
+)
+
+var knownBandChangeRules = map[string]map[CategoryTransmitter]BandChangeRules{
+	"CQ-WW-":  {OneTransmitter: TenMinuteRule, TwoTransmitter: EightChangesPerHour},
+	"CQ-WPX-": {OneTransmitter: TenMinuteRule, TwoTransmitter: EightChangesPerHour},
+}
+
+// BandChangeRulesFor returns the band change rules of a known contest for multi-operator
+// stations in the given transmitter category.
+func BandChangeRulesFor(contest ContestIdentifier, transmitter CategoryTransmitter) (BandChangeRules, bool) {
+	identifier := strings.ToUpper(strings.TrimSpace(string(contest)))
+	for prefix, rules := range knownBandChangeRules {
+		if !strings.HasPrefix(identifier, prefix) {
+			continue
+		}
+		result, ok := rules[transmitter]
+		return result, ok
+	}
+	return BandChangeRules{}, false
+}
+
+// BandChangeViolation describes a violation of the band change rules. QSOs contains the indices of the offending QSOs.
+type BandChangeViolation struct {
+	Transmitter int
+	QSOs        []int
+	Message     string
+}
+
+func (v BandChangeViolation) String() string {
+	qsos := make([]string, len(v.QSOs))
+	for i, index := range v.QSOs {
+		qsos[i] = fmt.Sprintf("%d", index+1)
+	}
+	return fmt.Sprintf("transmitter %d, QSO %s: %s", v.Transmitter, strings.Join(qsos, ", "), v.Message)
+}
+
+// Check walks through the QSOs of each transmitter in chronological order and reports all violations of the rules.
+// The result is ordered by transmitter.
+func (r BandChangeRules) Check(qsos []QSO) []BandChangeViolation {
+	var transmitters []int
+	qsosByTransmitter := make(map[int][]int)
+	for i, qso := range qsos {
+		if _, found := qsosByTransmitter[qso.Transmitter]; !found {
+			transmitters = append(transmitters, qso.Transmitter)
+		}
+		qsosByTransmitter[qso.Transmitter] = append(qsosByTransmitter[qso.Transmitter], i)
+	}
+	slices.Sort(transmitters)
+
+	var result []BandChangeViolation
+	for _, transmitter := range transmitters {
+		indices := qsosByTransmitter[transmitter]
+		slices.SortStableFunc(indices, func(a, b int) int {
+			return qsos[a].Timestamp.Compare(qsos[b].Timestamp)
+		})
+		result = append(result, r.checkTransmitter(transmitter, qsos, indices)...)
+	}
+	return result
+}
+
+func (r BandChangeRules) checkTransmitter(transmitter int, qsos []QSO, indices []int) []BandChangeViolation {
+	var result []BandChangeViolation
+	var currentBand CategoryBand
+	var bandStart time.Time
+	var currentHour time.Time
+	changesInHour := 0
+	for i, index := range indices {
+		qso := qsos[index]
+		band := qso.Frequency.ToBand()
+		if i == 0 {
+			currentBand = band
+			bandStart = qso.Timestamp
+			continue
+		}
+		if band == currentBand {
+			continue
+		}
+
+		previous := indices[i-1]
+		if r.MinimumDwell > 0 {
+			dwell := qso.Timestamp.Sub(bandStart)
+			if dwell < r.MinimumDwell {
+				result = append(result, BandChangeViolation{
+					Transmitter: transmitter,
+					QSOs:        []int{previous, index},
+					Message:     fmt.Sprintf("changed from %s to %s after %v on the band, minimum is %v", currentBand, band, dwell, r.MinimumDwell),
+				})
+			}
+		}
+
+		hour := qso.Timestamp.Truncate(time.Hour)
+		if !hour.Equal(currentHour) {
+			currentHour = hour
+			changesInHour = 0
+		}
+		changesInHour++
+		if r.MaxChangesPerHour > 0 && changesInHour > r.MaxChangesPerHour {
+			result = append(result, BandChangeViolation{
+				Transmitter: transmitter,
+				QSOs:        []int{index},
+				Message:     fmt.Sprintf("band change %d within the hour %s, maximum is %d", changesInHour, hour.UTC().Format("2006-01-02 15"), r.MaxChangesPerHour),
+			})
+		}
+
+		currentBand = band
+		bandStart = qso.Timestamp
+	}
+	return result
+}
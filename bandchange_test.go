@@ -0,0 +1,60 @@
+package cabrillo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bandChangeTestQSO(minute int, frequency QSOFrequency, transmitter int) QSO {
+	return QSO{
+		Frequency:   frequency,
+		Mode:        QSOModeCW,
+		Timestamp:   time.Date(2024, time.November, 23, 0, 0, 0, 0, time.UTC).Add(time.Duration(minute) * time.Minute),
+		Transmitter: transmitter,
+	}
+}
+
+func TestBandChangeRules_TenMinuteRule(t *testing.T) {
+	qsos := []QSO{
+		bandChangeTestQSO(0, "14025", 0),
+		bandChangeTestQSO(1, "7025", 1),
+		bandChangeTestQSO(5, "14026", 0),
+		bandChangeTestQSO(10, "21025", 0),
+		bandChangeTestQSO(12, "14027", 0),
+		bandChangeTestQSO(12, "3525", 1),
+	}
+
+	actual := TenMinuteRule.Check(qsos)
+
+	assert.Equal(t, []BandChangeViolation{
+		{Transmitter: 0, QSOs: []int{3, 4}, Message: "changed from 15M to 20M after 2m0s on the band, minimum is 10m0s"},
+	}, actual)
+}
+
+func TestBandChangeRules_MaxChangesPerHour(t *testing.T) {
+	rules := BandChangeRules{MaxChangesPerHour: 2}
+	qsos := []QSO{
+		bandChangeTestQSO(0, "14025", 0),
+		bandChangeTestQSO(10, "7025", 0),
+		bandChangeTestQSO(20, "14025", 0),
+		bandChangeTestQSO(30, "7025", 0),
+		bandChangeTestQSO(61, "14025", 0),
+	}
+
+	actual := rules.Check(qsos)
+
+	assert.Equal(t, []BandChangeViolation{
+		{Transmitter: 0, QSOs: []int{3}, Message: "band change 3 within the hour 2024-11-23 00, maximum is 2"},
+	}, actual)
+}
+
+func TestBandChangeRulesFor(t *testing.T) {
+	rules, ok := BandChangeRulesFor("CQ-WW-CW", OneTransmitter)
+	assert.True(t, ok)
+	assert.Equal(t, TenMinuteRule, rules)
+
+	_, ok = BandChangeRulesFor("CQ-WW-CW", UnlimitedTransmitter)
+	assert.False(t, ok)
+}
@@ -4,6 +4,7 @@
 package cabrillo
 
 import (
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,7 +23,7 @@ const (
 func NewLog() *Log {
 	return &Log{
 		CabrilloVersion: Version3,
-		Custom:          make(map[Tag]string),
+		Custom:          CustomTags{},
 		QSOData:         []QSO{},
 		IgnoredQSOs:     []QSO{},
 	}
@@ -47,9 +48,16 @@ type Log struct {
 	Offtime         Offtime
 	Soapbox         string
 	Debug           int
-	Custom          map[Tag]string
+	Custom          CustomTags
+	Extensions      Extensions
 	QSOData         []QSO
 	IgnoredQSOs     []QSO
+
+	// extensionLines are the raw values of the registered tags as they were read, in their original order.
+	extensionLines CustomTags
+
+	// Period is the time period of the contest. It is not part of the Cabrillo file, see DetectPeriod and CheckPeriod.
+	Period Period
 }
 
 type Tag string
@@ -61,6 +69,76 @@ func (t Tag) IsCustom() bool {
 	return strings.HasPrefix(string(t), XPrefix)
 }
 
+// CustomValue is the value of one line with a custom or unknown tag.
+type CustomValue struct {
+	Tag   Tag
+	Value string
+}
+
+// CustomTags holds the values of all custom and unknown tags in the order in which they were read.
+// Each line of a repeated tag is stored as a separate value.
+type CustomTags []CustomValue
+
+// Tags returns the distinct tags in the order of their first occurrence.
+func (c CustomTags) Tags() []Tag {
+	result := make([]Tag, 0, len(c))
+	for _, value := range c {
+		if !slices.Contains(result, value.Tag) {
+			result = append(result, value.Tag)
+		}
+	}
+	return result
+}
+
+// Get returns the first value of the given tag.
+func (c CustomTags) Get(tag Tag) (string, bool) {
+	for _, value := range c {
+		if value.Tag == tag {
+			return value.Value, true
+		}
+	}
+	return "", false
+}
+
+// Values returns all values of the given tag in their original order.
+func (c CustomTags) Values(tag Tag) []string {
+	var result []string
+	for _, value := range c {
+		if value.Tag == tag {
+			result = append(result, value.Value)
+		}
+	}
+	return result
+}
+
+// Add appends a value for the given tag.
+func (c *CustomTags) Add(tag Tag, value string) {
+	*c = append(*c, CustomValue{Tag: tag, Value: value})
+}
+
+// Set replaces all values of the given tag. The new values take the position of the first
+// former value, or they are appended if the tag was not present before.
+func (c *CustomTags) Set(tag Tag, values ...string) {
+	index := slices.IndexFunc(*c, func(value CustomValue) bool { return value.Tag == tag })
+	if index == -1 {
+		for _, value := range values {
+			c.Add(tag, value)
+		}
+		return
+	}
+	c.Delete(tag)
+	newValues := make([]CustomValue, len(values))
+	for i, value := range values {
+		newValues[i] = CustomValue{Tag: tag, Value: value}
+	}
+	*c = slices.Insert(*c, index, newValues...)
+}
+
+// Delete removes all values of the given tag.
+func (c *CustomTags) Delete(tag Tag) {
+	*c = slices.DeleteFunc(*c, func(value CustomValue) bool { return value.Tag == tag })
+}
+
 const (
 	StartOfLogTag           Tag = "START-OF-LOG"
 	EndOfLogTag             Tag = "END-OF-LOG"
@@ -239,7 +317,11 @@ const (
 	YLOverlay         CategoryOverlay = "YL"
 )
 
+// MaxAddressLines is the maximum number of ADDRESS lines allowed by the specification.
+const MaxAddressLines = 6
+
 type Address struct {
+	// Text contains the street lines of the address, separated by "\n".
 	Text          string
 	City          string
 	StateProvince string
@@ -247,6 +329,14 @@ type Address struct {
 	Country       string
 }
 
+// Lines returns the street lines of the address.
+func (a Address) Lines() []string {
+	if a.Text == "" {
+		return nil
+	}
+	return strings.Split(a.Text, "\n")
+}
+
 type Offtime struct {
 	Begin time.Time
 	End   time.Time
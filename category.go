@@ -0,0 +1,264 @@
+package cabrillo
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ftl/hamradio/callsign"
+)
+
+// Activity summarizes what can be derived from the QSO data of a log to determine the category.
+type Activity struct {
+	// Bands contains all bands with QSOs in the order of their first QSO.
+	Bands []CategoryBand
+	// Modes contains all category modes with QSOs in the order of their first QSO.
+	Modes []CategoryMode
+	// Transmitters contains all transmitter IDs in ascending order.
+	Transmitters []int
+	// Operators is the number of distinct operators listed in the header, without the host station.
+	Operators int
+	// Station is the station category that is indicated by the QSO data: ROVER if the sent locator changes,
+	// MOBILE or PORTABLE if the sent call has the respective working condition. Station is empty if the
+	// QSO data gives no indication.
+	Station CategoryStation
+
+	qsosPerBand        map[CategoryBand]int
+	qsosPerMode        map[CategoryMode]int
+	qsosPerTransmitter map[int]int
+}
+
+// ToCategoryMode returns the category mode that covers the given QSO mode.
+func (m QSOMode) ToCategoryMode() CategoryMode {
+	switch QSOMode(strings.ToUpper(string(m))) {
+	case QSOModeCW:
+		return ModeCW
+	case QSOModePhone:
+		return ModeSSB
+	case QSOModeFM:
+		return ModeFM
+	case QSOModeRTTY:
+		return ModeRTTY
+	case QSOModeDigi:
+		return ModeDIGI
+	default:
+		return CategoryMode(strings.ToUpper(string(m)))
+	}
+}
+
+// AnalyzeActivity derives the effective bands, modes, transmitters and operators from the given log.
+func AnalyzeActivity(l *Log) Activity {
+	result := Activity{
+		qsosPerBand:        make(map[CategoryBand]int),
+		qsosPerMode:        make(map[CategoryMode]int),
+		qsosPerTransmitter: make(map[int]int),
+	}
+	for _, qso := range l.QSOData {
+		band := qso.Frequency.ToBand()
+		if result.qsosPerBand[band] == 0 {
+			result.Bands = append(result.Bands, band)
+		}
+		result.qsosPerBand[band]++
+
+		mode := qso.Mode.ToCategoryMode()
+		if result.qsosPerMode[mode] == 0 {
+			result.Modes = append(result.Modes, mode)
+		}
+		result.qsosPerMode[mode]++
+
+		if result.qsosPerTransmitter[qso.Transmitter] == 0 {
+			result.Transmitters = append(result.Transmitters, qso.Transmitter)
+		}
+		result.qsosPerTransmitter[qso.Transmitter]++
+	}
+	slices.Sort(result.Transmitters)
+
+	result.Operators = len(listedOperators(l))
+	result.Station = stationOf(l)
+
+	return result
+}
+
+// listedOperators returns the distinct operators of the given log. The host station is not an operator, unless it
+// is also listed without the @, i.e. it appears more than once in the operators of the log.
+func listedOperators(l *Log) []callsign.Callsign {
+	result := make([]callsign.Callsign, 0, len(l.Operators))
+	distinct := make(map[string]bool, len(l.Operators))
+	hostSeen := false
+	for _, operator := range l.Operators {
+		if l.Host != callsign.NoCallsign && operator == l.Host && !hostSeen {
+			hostSeen = true
+			continue
+		}
+		call := formatCallsign(operator)
+		if distinct[call] {
+			continue
+		}
+		distinct[call] = true
+		result = append(result, operator)
+	}
+	return result
+}
+
+// stationOf derives the station category from the sent calls and the sent locators of the given log.
+func stationOf(l *Log) CategoryStation {
+	if definition, ok := ExchangeDefinitionFor(l.Contest); ok && definition.Index(LocatorField) != -1 {
+		locators := make(map[string]bool)
+		for _, qso := range l.QSOData {
+			value, err := qso.Sent.Element(definition, LocatorField)
+			if err == nil {
+				locators[strings.ToUpper(value)] = true
+			}
+		}
+		if len(locators) > 1 {
+			return RoverStation
+		}
+	}
+
+	for _, qso := range l.QSOData {
+		switch strings.ToUpper(qso.Sent.Call.WorkingCondition) {
+		case "M", "MM", "AM":
+			return MobileStation
+		case "P":
+			return PortableStation
+		}
+	}
+	return ""
+}
+
+// matchesStation indicates if the declared station category covers the derived station category.
+func matchesStation(declared CategoryStation, derived CategoryStation) bool {
+	switch derived {
+	case "":
+		return true
+	case RoverStation:
+		return declared == RoverStation || declared == RoverLimitedStation || declared == RoverUnlimitedStation
+	default:
+		return declared == derived
+	}
+}
+
+// CheckCategory reports every mismatch between the declared category and the QSO data of the given log.
+func CheckCategory(l *Log) []Problem {
+	var result []Problem
+	activity := AnalyzeActivity(l)
+	category := l.Category
+
+	switch category.Band {
+	case "", BandAll, BandVHF_3Band, BandVHF_FMOnly:
+	default:
+		for _, band := range activity.Bands {
+			if band != category.Band {
+				result = append(result, headerProblem(CategoryBandTag, "%s, but %d QSOs on %s", category.Band, activity.qsosPerBand[band], band))
+			}
+		}
+	}
+
+	switch category.Mode {
+	case "", ModeMIXED:
+	default:
+		for _, mode := range activity.Modes {
+			if mode != category.Mode {
+				result = append(result, headerProblem(CategoryModeTag, "%s, but %d QSOs in %s", category.Mode, activity.qsosPerMode[mode], mode))
+			}
+		}
+	}
+
+	var allowedTransmitters int
+	switch category.Transmitter {
+	case OneTransmitter:
+		allowedTransmitters = 1
+	case TwoTransmitter:
+		allowedTransmitters = 2
+	}
+	if allowedTransmitters > 0 {
+		for _, transmitter := range activity.Transmitters {
+			if transmitter < 0 || transmitter >= allowedTransmitters {
+				result = append(result, headerProblem(CategoryTransmitterTag, "%s, but %d QSOs with transmitter %d", category.Transmitter, activity.qsosPerTransmitter[transmitter], transmitter))
+			}
+		}
+	}
+
+	if category.Operator == SingleOperator && activity.Operators > 1 {
+		result = append(result, headerProblem(CategoryOperatorTag, "%s, but %d operators", category.Operator, activity.Operators))
+	}
+	if category.Operator == SingleOperator && len(activity.Transmitters) > 1 {
+		result = append(result, headerProblem(CategoryOperatorTag, "%s, but %d transmitters", category.Operator, len(activity.Transmitters)))
+	}
+	if category.Operator == MultiOperator && activity.Operators == 1 {
+		result = append(result, headerProblem(CategoryOperatorTag, "%s, but only one operator", category.Operator))
+	}
+
+	if category.Station != "" && !matchesStation(category.Station, activity.Station) {
+		result = append(result, headerProblem(CategoryStationTag, "%s, but the QSO data indicates %s", category.Station, activity.Station))
+	}
+
+	return result
+}
+
+// SuggestCategory returns the category that matches the QSO data of the given log. The parts of the category
+// that cannot be derived from the QSO data (assisted, power, time, overlay) are taken from the declared category,
+// as well as the station category if the QSO data gives no indication.
+func SuggestCategory(l *Log) Category {
+	activity := AnalyzeActivity(l)
+	result := l.Category
+
+	switch {
+	case len(activity.Bands) == 1 && isSingleBandCategory(activity.Bands[0]):
+		result.Band = activity.Bands[0]
+	case len(activity.Bands) == 0 && result.Band != "":
+	default:
+		result.Band = BandAll
+	}
+
+	switch len(activity.Modes) {
+	case 0:
+	case 1:
+		result.Mode = activity.Modes[0]
+	default:
+		result.Mode = ModeMIXED
+	}
+
+	switch {
+	case len(activity.Transmitters) == 0:
+	case len(activity.Transmitters) == 1 && activity.Transmitters[0] == 0:
+		result.Transmitter = OneTransmitter
+	case len(activity.Transmitters) <= 2 && activity.Transmitters[len(activity.Transmitters)-1] <= 1:
+		result.Transmitter = TwoTransmitter
+	default:
+		result.Transmitter = UnlimitedTransmitter
+	}
+
+	if !matchesStation(result.Station, activity.Station) {
+		result.Station = activity.Station
+	}
+
+	if result.Operator != Checklog {
+		switch {
+		case activity.Operators > 1 || len(activity.Transmitters) > 1:
+			result.Operator = MultiOperator
+		case activity.Operators == 1:
+			result.Operator = SingleOperator
+		}
+	}
+
+	return result
+}
+
+func isSingleBandCategory(band CategoryBand) bool {
+	switch band {
+	case BandAll, BandVHF_3Band, BandVHF_FMOnly:
+		return false
+	default:
+		return slices.Contains(categoryBandValues, band)
+	}
+}
+
+// String returns the category in the order of the CATEGORY-* tags, omitting empty values.
+func (c Category) String() string {
+	values := []string{
+		string(c.Operator), string(c.Assisted), string(c.Band), string(c.Mode),
+		string(c.Power), string(c.Station), string(c.Time), string(c.Transmitter), string(c.Overlay),
+	}
+	values = slices.DeleteFunc(values, func(s string) bool { return s == "" })
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,200 @@
+package cabrillo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func categoryTestLog(category Category, operators []string, qsos ...QSO) *Log {
+	result := NewLog()
+	result.Callsign = callsign.MustParse("DL0ABC")
+	result.Category = category
+	for _, operator := range operators {
+		result.Operators = append(result.Operators, callsign.MustParse(operator))
+	}
+	result.QSOData = qsos
+	return result
+}
+
+func categoryTestQSO(frequency QSOFrequency, mode QSOMode, transmitter int) QSO {
+	return QSO{Frequency: frequency, Mode: mode, Transmitter: transmitter}
+}
+
+func vhfTestLog(category Category, qsos ...QSO) *Log {
+	result := categoryTestLog(category, nil, qsos...)
+	result.Contest = "CQ-VHF"
+	return result
+}
+
+func stationTestQSO(call string, locator string) QSO {
+	return QSO{
+		Frequency: Frequency144MHz,
+		Mode:      QSOModeCW,
+		Sent:      QSOInfo{Call: callsign.MustParse(call), Exchange: []string{locator}},
+	}
+}
+
+func TestCheckCategory(t *testing.T) {
+	tt := []struct {
+		desc     string
+		log      *Log
+		expected []Problem
+	}{
+		{
+			desc: "matching",
+			log: categoryTestLog(Category{Band: Band20m, Mode: ModeCW, Operator: SingleOperator, Transmitter: OneTransmitter}, []string{"DL1ABC"},
+				categoryTestQSO("14025", QSOModeCW, 0),
+				categoryTestQSO("14030", QSOModeCW, 0),
+			),
+		},
+		{
+			desc: "wrong band",
+			log: categoryTestLog(Category{Band: Band20m}, nil,
+				categoryTestQSO("14025", QSOModeCW, 0),
+				categoryTestQSO("7025", QSOModeCW, 0),
+				categoryTestQSO("7030", QSOModeCW, 0),
+			),
+			expected: []Problem{{Tag: CategoryBandTag, QSO: -1, Message: "20M, but 2 QSOs on 40M"}},
+		},
+		{
+			desc: "wrong mode",
+			log: categoryTestLog(Category{Mode: ModeCW}, nil,
+				categoryTestQSO("14025", QSOModeCW, 0),
+				categoryTestQSO("14250", QSOModePhone, 0),
+			),
+			expected: []Problem{{Tag: CategoryModeTag, QSO: -1, Message: "CW, but 1 QSOs in SSB"}},
+		},
+		{
+			desc: "too many transmitters",
+			log: categoryTestLog(Category{Transmitter: OneTransmitter}, nil,
+				categoryTestQSO("14025", QSOModeCW, 0),
+				categoryTestQSO("7025", QSOModeCW, 1),
+			),
+			expected: []Problem{{Tag: CategoryTransmitterTag, QSO: -1, Message: "ONE, but 1 QSOs with transmitter 1"}},
+		},
+		{
+			desc:     "single operator with more operators",
+			log:      categoryTestLog(Category{Operator: SingleOperator}, []string{"DL1ABC", "DL2ABC"}),
+			expected: []Problem{{Tag: CategoryOperatorTag, QSO: -1, Message: "SINGLE-OP, but 2 operators"}},
+		},
+		{
+			desc: "every mismatch of the operator category",
+			log: categoryTestLog(Category{Operator: SingleOperator}, []string{"DL1ABC", "DL2ABC"},
+				categoryTestQSO("14025", QSOModeCW, 0),
+				categoryTestQSO("7025", QSOModeCW, 1),
+			),
+			expected: []Problem{
+				{Tag: CategoryOperatorTag, QSO: -1, Message: "SINGLE-OP, but 2 operators"},
+				{Tag: CategoryOperatorTag, QSO: -1, Message: "SINGLE-OP, but 2 transmitters"},
+			},
+		},
+		{
+			desc: "fixed station with portable call",
+			log: categoryTestLog(Category{Station: FixedStation}, nil,
+				stationTestQSO("DL1ABC/P", "JO62"),
+			),
+			expected: []Problem{{Tag: CategoryStationTag, QSO: -1, Message: "FIXED, but the QSO data indicates PORTABLE"}},
+		},
+		{
+			desc: "rover",
+			log: vhfTestLog(Category{Station: RoverLimitedStation},
+				stationTestQSO("DL1ABC/M", "JO62"),
+				stationTestQSO("DL1ABC/M", "JO63"),
+			),
+		},
+		{
+			desc: "fixed station with changing locator",
+			log: vhfTestLog(Category{Station: FixedStation},
+				stationTestQSO("DL1ABC", "JO62"),
+				stationTestQSO("DL1ABC", "JO63"),
+			),
+			expected: []Problem{{Tag: CategoryStationTag, QSO: -1, Message: "FIXED, but the QSO data indicates ROVER"}},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CheckCategory(tc.log))
+		})
+	}
+}
+
+func TestSuggestCategory(t *testing.T) {
+	log := categoryTestLog(Category{Band: Band20m, Mode: ModeCW, Operator: SingleOperator, Transmitter: OneTransmitter, Power: LowPower}, []string{"DL1ABC", "DL2ABC"},
+		categoryTestQSO("14025", QSOModeCW, 0),
+		categoryTestQSO("7025", QSOModeRTTY, 1),
+	)
+
+	actual := SuggestCategory(log)
+
+	assert.Equal(t, Category{Band: BandAll, Mode: ModeMIXED, Operator: MultiOperator, Transmitter: TwoTransmitter, Power: LowPower}, actual)
+	assert.Equal(t, "MULTI-OP ALL MIXED LOW TWO", actual.String())
+}
+
+func TestSuggestCategory_Station(t *testing.T) {
+	tests := []struct {
+		desc     string
+		declared CategoryStation
+		qsos     []QSO
+		expected CategoryStation
+	}{
+		{
+			desc:     "no indication",
+			declared: FixedStation,
+			qsos:     []QSO{stationTestQSO("DL1ABC", "JO62")},
+			expected: FixedStation,
+		},
+		{
+			desc:     "portable",
+			declared: FixedStation,
+			qsos:     []QSO{stationTestQSO("DL1ABC/P", "JO62")},
+			expected: PortableStation,
+		},
+		{
+			desc:     "rover",
+			declared: MobileStation,
+			qsos:     []QSO{stationTestQSO("DL1ABC/M", "JO62"), stationTestQSO("DL1ABC/M", "JO63")},
+			expected: RoverStation,
+		},
+		{
+			desc:     "declared rover variant",
+			declared: RoverUnlimitedStation,
+			qsos:     []QSO{stationTestQSO("DL1ABC", "JO62"), stationTestQSO("DL1ABC", "JO63")},
+			expected: RoverUnlimitedStation,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			log := vhfTestLog(Category{Station: tc.declared}, tc.qsos...)
+
+			assert.Equal(t, tc.expected, SuggestCategory(log).Station)
+		})
+	}
+}
+
+func TestAnalyzeActivity_HostStation(t *testing.T) {
+	tests := []struct {
+		desc      string
+		operators string
+		expected  int
+	}{
+		{desc: "host is no operator", operators: "DL1ABC @DL0XYZ", expected: 1},
+		{desc: "host is also listed as operator", operators: "DL1ABC DL0XYZ @DL0XYZ", expected: 2},
+		{desc: "repeated host", operators: "@DL0XYZ DL1ABC @DL0XYZ", expected: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			log, err := Read(strings.NewReader("START-OF-LOG: 3.0\nCALLSIGN: DL0XYZ\nCATEGORY-OPERATOR: SINGLE-OP\nOPERATORS: " + tc.operators + "\nEND-OF-LOG:\n"))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, AnalyzeActivity(log).Operators)
+			if tc.expected == 1 {
+				assert.Empty(t, CheckCategory(log))
+				assert.Equal(t, SingleOperator, SuggestCategory(log).Operator)
+			}
+		})
+	}
+}
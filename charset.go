@@ -0,0 +1,165 @@
+package cabrillo
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset is the character encoding of a log file.
+type Charset string
+
+const (
+	UTF8        Charset = "UTF-8"
+	Latin1      Charset = "ISO-8859-1"
+	Windows1252 Charset = "WINDOWS-1252"
+)
+
+// utf8BOM is the byte order mark that some editors write at the beginning of UTF-8 files.
+const utf8BOM = "\uFEFF"
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 to unicode. Undefined bytes map to the corresponding
+// C1 control character, like in Latin-1.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+// DetectCharset detects the character encoding of the given data. Data that starts with a BOM or that is valid UTF-8
+// is UTF-8, data that contains characters from the range 0x80-0x9F is Windows-1252, everything else is Latin-1.
+func DetectCharset(data []byte) Charset {
+	if bytes.HasPrefix(data, []byte(utf8BOM)) || utf8.Valid(data) {
+		return UTF8
+	}
+	for _, b := range data {
+		if b >= 0x80 && b <= 0x9F {
+			return Windows1252
+		}
+	}
+	return Latin1
+}
+
+// DecodeString converts the given data from the given character encoding into a UTF-8 string. A BOM is removed.
+func DecodeString(data []byte, charset Charset) string {
+	switch charset {
+	case Latin1, Windows1252:
+		var b strings.Builder
+		b.Grow(len(data))
+		for _, c := range data {
+			if charset == Windows1252 && c >= 0x80 && c <= 0x9F {
+				b.WriteRune(windows1252[c-0x80])
+			} else {
+				b.WriteRune(rune(c))
+			}
+		}
+		return b.String()
+	default:
+		return strings.TrimPrefix(string(data), utf8BOM)
+	}
+}
+
+// decodeLine converts a line of a log file into UTF-8. The encoding is detected for each line separately,
+// so files that mix encodings, e.g. after merging, can be read as well.
+func decodeLine(line []byte) string {
+	return DecodeString(line, DetectCharset(line))
+}
+
+// transliterations contains the ASCII replacements for common non-ASCII characters.
+var transliterations = map[rune]string{
+	'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue", 'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss", 'ẞ': "SS",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Å': "A", 'Ā': "A", 'Ă': "A", 'Ą': "A", 'Æ': "AE",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a", 'æ': "ae",
+	'Ç': "C", 'Ć': "C", 'Č': "C", 'ç': "c", 'ć': "c", 'č': "c", 'Ď': "D", 'Đ': "D", 'Ð': "D", 'ď': "d", 'đ': "d", 'ð': "d",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ę': "E", 'Ě': "E", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'Ğ': "G", 'ğ': "g", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'İ': "I", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ı': "i",
+	'Ł': "L", 'Ľ': "L", 'ł': "l", 'ľ': "l", 'Ñ': "N", 'Ń': "N", 'Ň': "N", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ø': "O", 'Ő': "O", 'Œ': "OE", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ø': "o", 'ő': "o", 'œ': "oe",
+	'Ř': "R", 'ř': "r", 'Ś': "S", 'Š': "S", 'Ş': "S", 'ś': "s", 'š': "s", 'ş': "s", 'Ť': "T", 'Ţ': "T", 'ť': "t", 'ţ': "t", 'Þ': "TH", 'þ': "th",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ů': "U", 'Ű': "U", 'ù': "u", 'ú': "u", 'û': "u", 'ů': "u", 'ű': "u",
+	'Ý': "Y", 'Ÿ': "Y", 'ý': "y", 'ÿ': "y", 'Ź': "Z", 'Ż': "Z", 'Ž': "Z", 'ź': "z", 'ż': "z", 'ž': "z",
+	'‘': "'", '’': "'", '‚': "'", '‹': "<", '›': ">", '“': "\"", '”': "\"", '„': "\"", '«': "<<", '»': ">>",
+	'–': "-", '—': "-", '…': "...", '•': "*", '·': ".", '€': "EUR", '£': "GBP", '°': "deg", '×': "x",
+	'©': "(C)", '®': "(R)", '™': "(TM)", '\u00A0': " ",
+}
+
+// Transliterate replaces all non-ASCII characters in the given string with ASCII equivalents, e.g. ß with ss,
+// ü with ue and é with e. Characters without an equivalent are replaced with '?'.
+func Transliterate(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, c := range s {
+		switch replacement, found := transliterations[c]; {
+		case c < utf8.RuneSelf:
+			b.WriteRune(c)
+		case found:
+			b.WriteString(replacement)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// transliteratedLog returns a copy of the given log with all text values transliterated into ASCII. The values
+// are transliterated before the log is written, since the replacements may be longer than the original characters
+// and must be taken into account when long lines are wrapped and the QSO columns are aligned.
+func transliteratedLog(l *Log) *Log {
+	result := *l
+
+	result.CabrilloVersion = Transliterate(l.CabrilloVersion)
+	result.Contest = ContestIdentifier(Transliterate(string(l.Contest)))
+	result.Category = Category{
+		Assisted:    CategoryAssisted(Transliterate(string(l.Category.Assisted))),
+		Band:        CategoryBand(Transliterate(string(l.Category.Band))),
+		Mode:        CategoryMode(Transliterate(string(l.Category.Mode))),
+		Operator:    CategoryOperator(Transliterate(string(l.Category.Operator))),
+		Power:       CategoryPower(Transliterate(string(l.Category.Power))),
+		Station:     CategoryStation(Transliterate(string(l.Category.Station))),
+		Time:        CategoryTime(Transliterate(string(l.Category.Time))),
+		Transmitter: CategoryTransmitter(Transliterate(string(l.Category.Transmitter))),
+		Overlay:     CategoryOverlay(Transliterate(string(l.Category.Overlay))),
+	}
+	result.Club = Transliterate(l.Club)
+	result.CreatedBy = Transliterate(l.CreatedBy)
+	result.Email = Transliterate(l.Email)
+	result.Location = Transliterate(l.Location)
+	result.Name = Transliterate(l.Name)
+	result.Address = Address{
+		Text:          Transliterate(l.Address.Text),
+		City:          Transliterate(l.Address.City),
+		StateProvince: Transliterate(l.Address.StateProvince),
+		Postalcode:    Transliterate(l.Address.Postalcode),
+		Country:       Transliterate(l.Address.Country),
+	}
+	result.Soapbox = Transliterate(l.Soapbox)
+
+	result.Custom = make(CustomTags, len(l.Custom))
+	for i, value := range l.Custom {
+		result.Custom[i] = CustomValue{Tag: Tag(Transliterate(string(value.Tag))), Value: Transliterate(value.Value)}
+	}
+
+	result.QSOData = transliteratedQSOs(l.QSOData)
+	result.IgnoredQSOs = transliteratedQSOs(l.IgnoredQSOs)
+
+	return &result
+}
+
+func transliteratedQSOs(qsos []QSO) []QSO {
+	result := make([]QSO, len(qsos))
+	for i, qso := range qsos {
+		qso.Frequency = QSOFrequency(Transliterate(string(qso.Frequency)))
+		qso.Mode = QSOMode(Transliterate(string(qso.Mode)))
+		qso.Sent.Exchange = transliteratedValues(qso.Sent.Exchange)
+		qso.Received.Exchange = transliteratedValues(qso.Received.Exchange)
+		result[i] = qso
+	}
+	return result
+}
+
+func transliteratedValues(values []string) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = Transliterate(value)
+	}
+	return result
+}
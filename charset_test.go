@@ -0,0 +1,111 @@
+package cabrillo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCharset(t *testing.T) {
+	tt := []struct {
+		desc     string
+		data     []byte
+		expected Charset
+	}{
+		{desc: "ASCII", data: []byte("Musterstadt"), expected: UTF8},
+		{desc: "UTF-8", data: []byte("Beispielstraße"), expected: UTF8},
+		{desc: "UTF-8 with BOM", data: []byte("\xef\xbb\xbfSTART-OF-LOG: 3.0"), expected: UTF8},
+		{desc: "Latin-1", data: []byte("Beispielstra\xdfe"), expected: Latin1},
+		{desc: "Windows-1252", data: []byte("\x93M\xfcller\x94"), expected: Windows1252},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DetectCharset(tc.data))
+		})
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	assert.Equal(t, "START-OF-LOG: 3.0", DecodeString([]byte("\xef\xbb\xbfSTART-OF-LOG: 3.0"), UTF8))
+	assert.Equal(t, "Beispielstraße", DecodeString([]byte("Beispielstra\xdfe"), Latin1))
+	assert.Equal(t, "“Müller” 5€", DecodeString([]byte("\x93M\xfcller\x94 5\x80"), Windows1252))
+}
+
+func TestRead_MixedCharsets(t *testing.T) {
+	input := "\xef\xbb\xbfSTART-OF-LOG: 3.0\nNAME: J\xf6rg M\xfcller\nADDRESS: Beispielstraße 1\nSOAPBOX: \x84Gut\x93\nEND-OF-LOG:\n"
+
+	log, err := Read(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, "3.0", log.CabrilloVersion)
+	assert.Equal(t, "Jörg Müller", log.Name)
+	assert.Equal(t, "Beispielstraße 1", log.Address.Text)
+	assert.Equal(t, "„Gut“", log.Soapbox)
+}
+
+func TestTransliterate(t *testing.T) {
+	tt := []struct {
+		value    string
+		expected string
+	}{
+		{"Musterstadt", "Musterstadt"},
+		{"Beispielstraße", "Beispielstrasse"},
+		{"Jörg Müller", "Joerg Mueller"},
+		{"ÄÖÜ", "AeOeUe"},
+		{"Café Señor Łódź", "Cafe Senor Lodz"},
+		{"„Gut“ – 5€", "\"Gut\" - 5EUR"},
+		{"東京", "??"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Transliterate(tc.value))
+		})
+	}
+}
+
+func TestWriter_Transliterate(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Address.Text = "Beispielstraße 1"
+	log.Custom.Add("X-NOTE", "grüße")
+	writer := NewWriter(WithTransliteration(true))
+	buffer := &bytes.Buffer{}
+
+	err := writer.WriteWithTags(buffer, log, false, true, AddressTag, "X-NOTE")
+	require.NoError(t, err)
+
+	assert.Equal(t, "START-OF-LOG: 3.0\nADDRESS: Beispielstrasse 1\nX-NOTE: gruesse\nEND-OF-LOG:\n", buffer.String())
+	assert.Equal(t, "Beispielstraße 1", log.Address.Text, "the log must not be modified")
+}
+
+func TestWriter_TransliterateBeforeWrapping(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Soapbox = strings.Repeat("ä", 60) + " grüße"
+	log.QSOData = []QSO{
+		{Frequency: "14025", Mode: QSOModeCW, Sent: QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "Jürgen"}}, Received: QSOInfo{Call: callsign.MustParse("W1AW"), Exchange: []string{"599", "Bob"}}},
+		{Frequency: "14026", Mode: QSOModeCW, Sent: QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "Jürgen"}}, Received: QSOInfo{Call: callsign.MustParse("K1AR"), Exchange: []string{"599", "Ed"}}},
+	}
+	writer := NewWriter(WithTransliteration(true), WithAlignedQSOs(true), WithTagOrder(SoapboxTag))
+	buffer := &bytes.Buffer{}
+
+	err := writer.WriteLog(buffer, log)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), maxLineLength, line)
+	}
+	assert.Equal(t, []string{
+		"START-OF-LOG: 3.0",
+		"SOAPBOX: " + strings.Repeat("ae", 33),
+		"SOAPBOX: " + strings.Repeat("ae", 27) + " gruesse",
+		"QSO: 14025 CW 0001-01-01 0000 DL1ABC 599 Juergen W1AW 599 Bob",
+		"QSO: 14026 CW 0001-01-01 0000 DL1ABC 599 Juergen K1AR 599 Ed",
+		"END-OF-LOG:",
+	}, lines)
+}
@@ -0,0 +1,106 @@
+package cabrillo
+
+import (
+	"slices"
+	"time"
+)
+
+// ShiftQSOs adds the given offset to the timestamps of the given QSOs. To shift only a range of QSOs,
+// pass a sub-slice, e.g. l.QSOData[100:200].
+func ShiftQSOs(qsos []QSO, offset time.Duration) {
+	for i := range qsos {
+		qsos[i].Timestamp = qsos[i].Timestamp.Add(offset)
+	}
+}
+
+// ShiftLog adds the given offset to the timestamps of all QSOs and to the offtime of the given log.
+func ShiftLog(l *Log, offset time.Duration) {
+	ShiftTimeRange(l, time.Time{}, time.Time{}, offset)
+}
+
+// ShiftTimeRange adds the given offset to all timestamps of the given log that are within [from, to), this includes
+// the QSO data and the ignored QSOs. The offtime is shifted as a whole if it begins within the range, so that it
+// never ends before it begins. A zero from or to leaves the range open on that side. It returns the number of
+// shifted QSOs.
+func ShiftTimeRange(l *Log, from, to time.Time, offset time.Duration) int {
+	inRange := func(t time.Time) bool {
+		return !t.IsZero() && (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	}
+
+	result := 0
+	for _, qsos := range [][]QSO{l.QSOData, l.IgnoredQSOs} {
+		for i := range qsos {
+			if !inRange(qsos[i].Timestamp) {
+				continue
+			}
+			qsos[i].Timestamp = qsos[i].Timestamp.Add(offset)
+			result++
+		}
+	}
+	if inRange(l.Offtime.Begin) && !l.Offtime.End.IsZero() {
+		l.Offtime.Begin = l.Offtime.Begin.Add(offset)
+		l.Offtime.End = l.Offtime.End.Add(offset)
+	}
+	return result
+}
+
+// ClockOffset is the estimated deviation of the clock that was used to create a log. A positive offset means
+// that the clock was ahead, use ShiftLog(l, -offset.Offset) to correct the log.
+type ClockOffset struct {
+	Offset time.Duration
+	// Matches is the number of QSOs that were found in the reference logs and used for the estimation.
+	Matches int
+}
+
+type clockMatchKey struct {
+	call string
+	band CategoryBand
+	mode QSOMode
+}
+
+// EstimateClockOffset estimates the clock offset of the given log by comparing the timestamps of its QSOs with the
+// timestamps of the same QSOs in the logs of the worked stations. A QSO matches if the reference log contains a QSO
+// with the log's station on the same band in the same mode, the closest one in time is used. The result is the median
+// of the differences, rounded to full minutes. It returns false if no QSO could be matched.
+func EstimateClockOffset(l *Log, references ...*Log) (ClockOffset, bool) {
+	ownCall := l.Callsign.String()
+	referenceTimestamps := make(map[clockMatchKey][]time.Time)
+	for _, reference := range references {
+		referenceCall := reference.Callsign.String()
+		for _, qso := range reference.QSOData {
+			if qso.Received.Call.String() != ownCall {
+				continue
+			}
+			key := clockMatchKey{call: referenceCall, band: qso.Frequency.ToBand(), mode: qso.Mode}
+			referenceTimestamps[key] = append(referenceTimestamps[key], qso.Timestamp)
+		}
+	}
+
+	var differences []time.Duration
+	for _, qso := range l.QSOData {
+		key := clockMatchKey{call: qso.Received.Call.String(), band: qso.Frequency.ToBand(), mode: qso.Mode}
+		timestamps, found := referenceTimestamps[key]
+		if !found {
+			continue
+		}
+		closest := qso.Timestamp.Sub(timestamps[0])
+		for _, timestamp := range timestamps[1:] {
+			difference := qso.Timestamp.Sub(timestamp)
+			if difference.Abs() < closest.Abs() {
+				closest = difference
+			}
+		}
+		differences = append(differences, closest)
+	}
+	if len(differences) == 0 {
+		return ClockOffset{}, false
+	}
+
+	slices.Sort(differences)
+	middle := len(differences) / 2
+	median := differences[middle]
+	if len(differences)%2 == 0 {
+		median = (differences[middle-1] + differences[middle]) / 2
+	}
+	return ClockOffset{Offset: median.Round(time.Minute), Matches: len(differences)}, true
+}
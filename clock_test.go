@@ -0,0 +1,148 @@
+package cabrillo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+)
+
+func clockTestLog(call string, qsos ...QSO) *Log {
+	result := NewLog()
+	result.Callsign = callsign.MustParse(call)
+	result.QSOData = qsos
+	return result
+}
+
+func clockTestQSO(minute int, frequency QSOFrequency, call string) QSO {
+	return QSO{
+		Frequency: frequency,
+		Mode:      QSOModeCW,
+		Timestamp: time.Date(2024, time.October, 26, 12, minute, 0, 0, time.UTC),
+		Received:  QSOInfo{Call: callsign.MustParse(call)},
+	}
+}
+
+func TestShiftTimeRange(t *testing.T) {
+	base := time.Date(2024, time.October, 26, 12, 0, 0, 0, time.UTC)
+	l := clockTestLog("DL1ABC",
+		clockTestQSO(0, "14025", "W1AW"),
+		clockTestQSO(10, "14025", "K1AR"),
+		clockTestQSO(20, "14025", "JA1ABC"),
+	)
+	l.IgnoredQSOs = []QSO{clockTestQSO(15, "14025", "OH2XX")}
+	l.Offtime = Offtime{Begin: base.Add(5 * time.Minute), End: base.Add(12 * time.Minute)}
+
+	shifted := ShiftTimeRange(l, base.Add(10*time.Minute), base.Add(20*time.Minute), time.Hour)
+
+	assert.Equal(t, 2, shifted)
+	assert.Equal(t, base, l.QSOData[0].Timestamp)
+	assert.Equal(t, base.Add(70*time.Minute), l.QSOData[1].Timestamp)
+	assert.Equal(t, base.Add(20*time.Minute), l.QSOData[2].Timestamp)
+	assert.Equal(t, base.Add(75*time.Minute), l.IgnoredQSOs[0].Timestamp)
+	assert.Equal(t, base.Add(5*time.Minute), l.Offtime.Begin, "the offtime begins before the range")
+	assert.Equal(t, base.Add(12*time.Minute), l.Offtime.End, "the offtime begins before the range")
+}
+
+func TestShiftTimeRange_Offtime(t *testing.T) {
+	base := time.Date(2024, time.October, 26, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		offtime       Offtime
+		offset        time.Duration
+		expectedBegin time.Time
+		expectedEnd   time.Time
+	}{
+		{
+			name:          "within the range",
+			offtime:       Offtime{Begin: base.Add(12 * time.Minute), End: base.Add(18 * time.Minute)},
+			offset:        time.Hour,
+			expectedBegin: base.Add(72 * time.Minute),
+			expectedEnd:   base.Add(78 * time.Minute),
+		},
+		{
+			name:          "begins within the range",
+			offtime:       Offtime{Begin: base.Add(15 * time.Minute), End: base.Add(25 * time.Minute)},
+			offset:        -10 * time.Minute,
+			expectedBegin: base.Add(5 * time.Minute),
+			expectedEnd:   base.Add(15 * time.Minute),
+		},
+		{
+			name:          "ends within the range",
+			offtime:       Offtime{Begin: base.Add(5 * time.Minute), End: base.Add(12 * time.Minute)},
+			offset:        -10 * time.Minute,
+			expectedBegin: base.Add(5 * time.Minute),
+			expectedEnd:   base.Add(12 * time.Minute),
+		},
+		{
+			name:          "outside the range",
+			offtime:       Offtime{Begin: base.Add(25 * time.Minute), End: base.Add(30 * time.Minute)},
+			offset:        time.Hour,
+			expectedBegin: base.Add(25 * time.Minute),
+			expectedEnd:   base.Add(30 * time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := clockTestLog("DL1ABC")
+			l.Offtime = tt.offtime
+
+			ShiftTimeRange(l, base.Add(10*time.Minute), base.Add(20*time.Minute), tt.offset)
+
+			assert.Equal(t, tt.expectedBegin, l.Offtime.Begin)
+			assert.Equal(t, tt.expectedEnd, l.Offtime.End)
+			assert.False(t, l.Offtime.End.Before(l.Offtime.Begin))
+		})
+	}
+}
+
+func TestShiftLog(t *testing.T) {
+	base := time.Date(2024, time.October, 26, 12, 0, 0, 0, time.UTC)
+	l := clockTestLog("DL1ABC", clockTestQSO(0, "14025", "W1AW"), clockTestQSO(10, "14025", "K1AR"))
+
+	ShiftLog(l, -2*time.Minute)
+
+	assert.Equal(t, base.Add(-2*time.Minute), l.QSOData[0].Timestamp)
+	assert.Equal(t, base.Add(8*time.Minute), l.QSOData[1].Timestamp)
+	assert.True(t, l.Offtime.Begin.IsZero(), "an empty offtime must stay empty")
+}
+
+func TestShiftQSOs(t *testing.T) {
+	base := time.Date(2024, time.October, 26, 12, 0, 0, 0, time.UTC)
+	qsos := []QSO{clockTestQSO(0, "14025", "W1AW"), clockTestQSO(10, "14025", "K1AR")}
+
+	ShiftQSOs(qsos[1:], time.Minute)
+
+	assert.Equal(t, base, qsos[0].Timestamp)
+	assert.Equal(t, base.Add(11*time.Minute), qsos[1].Timestamp)
+}
+
+func TestEstimateClockOffset(t *testing.T) {
+	l := clockTestLog("DL1ABC",
+		clockTestQSO(0, "14025", "W1AW"),
+		clockTestQSO(10, "7025", "W1AW"),
+		clockTestQSO(20, "14025", "K1AR"),
+		clockTestQSO(30, "14025", "JA1ABC"),
+	)
+	w1aw := clockTestLog("W1AW",
+		clockTestQSO(0, "21025", "DL1ABC"),
+		clockTestQSO(57, "14025", "DL1ABC"),
+		clockTestQSO(7, "7025", "DL1ABC"),
+	)
+	k1ar := clockTestLog("K1AR",
+		clockTestQSO(17, "14025", "DL1ABC"),
+		clockTestQSO(45, "14025", "DL2XYZ"),
+	)
+	ja1abc := clockTestLog("JA1ABC",
+		clockTestQSO(30, "14025", "DL9ZZZ"),
+	)
+
+	actual, ok := EstimateClockOffset(l, w1aw, k1ar, ja1abc)
+
+	assert.True(t, ok)
+	assert.Equal(t, ClockOffset{Offset: 3 * time.Minute, Matches: 3}, actual)
+
+	_, ok = EstimateClockOffset(l, ja1abc)
+	assert.False(t, ok)
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ftl/cabrillo"
+	"github.com/ftl/cabrillo/stats"
+)
+
+func runValidate(args []string) error {
+	flags := newFlagSet("validate", "<file>...")
+	asJSON := flags.Bool("json", false, "write the result as JSON")
+	err := parseFlags(flags, args, 1, 0)
+	if err != nil {
+		return err
+	}
+
+	type fileResult struct {
+		File     string   `json:"file"`
+		Error    string   `json:"error,omitempty"`
+		Problems []string `json:"problems"`
+	}
+	results := make([]fileResult, 0, flags.NArg())
+	valid := true
+	for _, filename := range flags.Args() {
+		result := fileResult{File: filename, Problems: []string{}}
+		l, err := readLog(filename)
+		if err != nil {
+			result.Error = err.Error()
+			valid = false
+		} else {
+			problems := cabrillo.Validate(l)
+			problems = append(problems, cabrillo.CheckCategory(l)...)
+			if cabrillo.DetectPeriod(l) {
+				problems = append(problems, cabrillo.CheckPeriod(l)...)
+			}
+			if definition, ok := cabrillo.ExchangeDefinitionFor(l.Contest); ok {
+				problems = append(problems, cabrillo.CheckSentData(l, definition)...)
+			}
+			for _, problem := range problems {
+				result.Problems = append(result.Problems, problem.String())
+				valid = false
+			}
+			if rules, ok := cabrillo.BandChangeRulesFor(l.Contest, l.Category.Transmitter); ok && l.Category.Operator == cabrillo.MultiOperator {
+				for _, violation := range rules.Check(l.QSOData) {
+					result.Problems = append(result.Problems, violation.String())
+					valid = false
+				}
+			}
+		}
+		results = append(results, result)
+	}
+
+	if *asJSON {
+		err = writeJSON(os.Stdout, results)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Println(result.Error)
+			}
+			for _, problem := range result.Problems {
+				fmt.Printf("%s: %s\n", result.File, problem)
+			}
+		}
+	}
+	if !valid {
+		return errProblemsFound
+	}
+	return nil
+}
+
+func runFmt(args []string) error {
+	flags := newFlagSet("fmt", "<file>")
+	inPlace := flags.Bool("w", false, "write the result back to the file instead of stdout")
+	crlf := flags.Bool("crlf", false, "use CRLF line endings instead of LF")
+	transmitter := flags.String("tx", "auto", "write the transmitter column: auto, always, never")
+	ignoreOutsidePeriod := flags.Bool("ignore-outside-period", false, "write QSOs outside the contest period as X-QSO")
+	sortQSOs := flags.Bool("sort", false, "write the QSOs in chronological order")
+	ascii := flags.Bool("ascii", false, "replace all non-ASCII characters with ASCII equivalents")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	formatter := cabrillo.Formatter{SortQSOs: *sortQSOs, Transliterate: *ascii}
+	if *crlf {
+		formatter.LineEnding = cabrillo.CRLF
+	}
+	switch strings.ToLower(*transmitter) {
+	case "auto":
+		formatter.Transmitter = cabrillo.TransmitterAuto
+	case "always":
+		formatter.Transmitter = cabrillo.TransmitterAlways
+	case "never":
+		formatter.Transmitter = cabrillo.TransmitterNever
+	default:
+		return fmt.Errorf("invalid value for -tx: %q", *transmitter)
+	}
+	filename := flags.Arg(0)
+	if *inPlace && filename == "-" {
+		return fmt.Errorf("cannot write back to stdin")
+	}
+
+	l, err := readLog(filename)
+	if err != nil {
+		return err
+	}
+	if *ignoreOutsidePeriod {
+		if !cabrillo.DetectPeriod(l) {
+			return fmt.Errorf("the period of the contest %s is unknown", l.Contest)
+		}
+		cabrillo.IgnoreQSOsOutsidePeriod(l)
+	}
+	if !*inPlace {
+		filename = ""
+	}
+	return formatLog(filename, l, formatter)
+}
+
+func runConvert(args []string) error {
+	flags := newFlagSet("convert", "<file>")
+	from := flags.String("from", "cabrillo", "the input format: cabrillo, csv")
+	to := flags.String("to", "json", "the output format: cabrillo, adif, csv, json")
+	mapping := flags.String("mapping", "", "the CSV column mapping, e.g. \"Freq=frequency, Call=rcvd_call\"")
+	output := flags.String("o", "", "the output file, default is stdout")
+	ascii := flags.Bool("ascii", false, "replace all non-ASCII characters with ASCII equivalents in Cabrillo output")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	var l *cabrillo.Log
+	switch strings.ToLower(*from) {
+	case "cabrillo":
+		l, err = readLog(flags.Arg(0))
+	case "csv":
+		l, err = readCSVLog(flags.Arg(0), *mapping)
+	default:
+		err = fmt.Errorf("unknown input format %q", *from)
+	}
+	if err != nil {
+		return err
+	}
+
+	if strings.ToLower(*to) == "cabrillo" {
+		return formatLog(*output, l, cabrillo.Formatter{Transliterate: *ascii})
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	switch strings.ToLower(*to) {
+	case "adif":
+		return cabrillo.WriteADIF(out, l)
+	case "csv":
+		return cabrillo.WriteCSV(out, l.QSOData)
+	case "json":
+		return writeJSON(out, toJSONLog(l))
+	default:
+		return fmt.Errorf("unknown output format %q", *to)
+	}
+}
+
+func readCSVLog(filename string, mappingDescription string) (*cabrillo.Log, error) {
+	var mapping cabrillo.CSVMapping
+	var err error
+	if mappingDescription != "" {
+		mapping, err = cabrillo.ParseCSVMapping(mappingDescription)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	qsos, rowErrors, err := cabrillo.ReadCSV(file, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(rowErrors) > 0 {
+		for _, rowError := range rowErrors {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filename, rowError)
+		}
+		return nil, errProblemsFound
+	}
+
+	result := cabrillo.NewLog()
+	result.QSOData = qsos
+	if len(qsos) > 0 {
+		result.Callsign = qsos[0].Sent.Call
+	}
+	return result, nil
+}
+
+func runStats(args []string) error {
+	flags := newFlagSet("stats", "<file>")
+	asJSON := flags.Bool("json", false, "write the result as JSON")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	result := stats.Compute(l, nil)
+	if *asJSON {
+		return result.WriteJSON(os.Stdout)
+	}
+	return result.WriteText(os.Stdout)
+}
+
+func runDupes(args []string) error {
+	flags := newFlagSet("dupes", "<file>")
+	asJSON := flags.Bool("json", false, "write the result as JSON")
+	perMode := flags.Bool("per-mode", false, "allow to work the same station on the same band in different modes")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	type dupe struct {
+		QSO      int     `json:"qso"`
+		Original int     `json:"original"`
+		Data     jsonQSO `json:"data"`
+	}
+	dupes := cabrillo.FindDupes(l.QSOData, *perMode)
+	result := make([]dupe, 0, len(dupes))
+	for _, d := range dupes {
+		result = append(result, dupe{QSO: d.Index + 1, Original: d.Original + 1, Data: toJSONQSO(l.QSOData[d.Index])})
+	}
+
+	if *asJSON {
+		err = writeJSON(os.Stdout, result)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, d := range result {
+			fmt.Printf("QSO %d is a dupe of QSO %d: %s %s %s %s\n", d.QSO, d.Original, d.Data.Timestamp, d.Data.Band, d.Data.Mode, d.Data.Received.Call)
+		}
+	}
+	if len(result) > 0 {
+		return errProblemsFound
+	}
+	return nil
+}
+
+func runOrder(args []string) error {
+	flags := newFlagSet("order", "<file>")
+	asJSON := flags.Bool("json", false, "write the result as JSON")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	type outOfOrder struct {
+		QSO         int     `json:"qso"`
+		Predecessor int     `json:"predecessor"`
+		Data        jsonQSO `json:"data"`
+	}
+	qsos := cabrillo.FindOutOfOrderQSOs(l.QSOData)
+	result := make([]outOfOrder, 0, len(qsos))
+	for _, q := range qsos {
+		result = append(result, outOfOrder{QSO: q.Index + 1, Predecessor: q.Predecessor + 1, Data: toJSONQSO(l.QSOData[q.Index])})
+	}
+
+	if *asJSON {
+		err = writeJSON(os.Stdout, result)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, q := range result {
+			fmt.Printf("QSO %d is earlier than QSO %d: %s %s %s %s\n", q.QSO, q.Predecessor, q.Data.Timestamp, q.Data.Band, q.Data.Mode, q.Data.Received.Call)
+		}
+	}
+	if len(result) > 0 {
+		return errProblemsFound
+	}
+	return nil
+}
+
+func runMerge(args []string) error {
+	flags := newFlagSet("merge", "<file> <file>...")
+	output := flags.String("o", "", "the output file, default is stdout")
+	err := parseFlags(flags, args, 2, 0)
+	if err != nil {
+		return err
+	}
+
+	var result *cabrillo.Log
+	for _, filename := range flags.Args() {
+		l, err := readLog(filename)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			result = l
+			continue
+		}
+		result.QSOData = append(result.QSOData, l.QSOData...)
+		result.IgnoredQSOs = append(result.IgnoredQSOs, l.IgnoredQSOs...)
+	}
+	cabrillo.SortLog(result)
+
+	return writeLog(*output, result)
+}
+
+func runRedact(args []string) error {
+	flags := newFlagSet("redact", "<file>")
+	drop := flags.String("drop", "", "comma-separated list of additional tags to drop")
+	hash := flags.String("hash", "", "comma-separated list of tags to replace with a hash")
+	mask := flags.String("mask", "", "comma-separated list of tags to mask")
+	keep := flags.String("keep", "", "comma-separated list of tags to keep, even if they are dropped by default, e.g. custom tags")
+	salt := flags.String("salt", "", "the salt that is used for hashing")
+	output := flags.String("o", "", "the output file, default is stdout")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	redaction := cabrillo.PublicRedaction()
+	redaction.Salt = *salt
+	for _, p := range []struct {
+		tags   string
+		policy cabrillo.RedactionPolicy
+	}{
+		{*drop, cabrillo.DropValue},
+		{*hash, cabrillo.HashValue},
+		{*mask, cabrillo.MaskValue},
+		{*keep, cabrillo.KeepValue},
+	} {
+		for _, tag := range strings.Split(p.tags, ",") {
+			tag = strings.ToUpper(strings.TrimSpace(tag))
+			if tag != "" {
+				redaction.Policies[cabrillo.Tag(tag)] = p.policy
+			}
+		}
+	}
+
+	return writeLog(*output, redaction.Redact(l))
+}
+
+func runShift(args []string) error {
+	flags := newFlagSet("shift", "<file>")
+	offset := flags.Duration("by", 0, "the offset that is added to the timestamps, e.g. -1h30m")
+	from := flags.String("from", "", "shift only timestamps at or after this time (yyyy-mm-dd hhmm)")
+	to := flags.String("to", "", "shift only timestamps before this time (yyyy-mm-dd hhmm)")
+	estimate := flags.String("estimate", "", "comma-separated list of reference logs to estimate the clock offset, nothing is shifted")
+	output := flags.String("o", "", "the output file, default is stdout")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *estimate != "" {
+		var references []*cabrillo.Log
+		for _, filename := range strings.Split(*estimate, ",") {
+			reference, err := readLog(strings.TrimSpace(filename))
+			if err != nil {
+				return err
+			}
+			references = append(references, reference)
+		}
+		offset, ok := cabrillo.EstimateClockOffset(l, references...)
+		if !ok {
+			return fmt.Errorf("no QSO found in the reference logs")
+		}
+		fmt.Printf("Clock offset: %s (%d matching QSOs)\n", offset.Offset, offset.Matches)
+		return nil
+	}
+
+	var fromTime, toTime time.Time
+	if *from != "" {
+		fromTime, err = cabrillo.ParseTimestamp(*from)
+		if err != nil {
+			return fmt.Errorf("invalid value for -from: %w", err)
+		}
+	}
+	if *to != "" {
+		toTime, err = cabrillo.ParseTimestamp(*to)
+		if err != nil {
+			return fmt.Errorf("invalid value for -to: %w", err)
+		}
+	}
+	cabrillo.ShiftTimeRange(l, fromTime, toTime, *offset)
+
+	return writeLog(*output, l)
+}
+
+func runRecover(args []string) error {
+	flags := newFlagSet("recover", "<file>")
+	output := flags.String("o", "", "the output file, or the prefix of the output files if the input contains several logs; default is stdout")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	file, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	logs, err := cabrillo.ReadAllRecover(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+	if len(logs) > 1 && *output == "" {
+		return fmt.Errorf("the input contains %d logs, an output prefix is required", len(logs))
+	}
+
+	for i, l := range logs {
+		for _, repair := range l.Repairs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", flags.Arg(0), repair)
+		}
+		filename := *output
+		if len(logs) > 1 {
+			filename = fmt.Sprintf("%s-%d.log", strings.TrimSuffix(*output, ".log"), i+1)
+		}
+		err = writeLog(filename, l.Log)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runSplit(args []string) error {
+	flags := newFlagSet("split", "<file>")
+	by := flags.String("by", "transmitter", "split the log by transmitter, band or mode")
+	prefix := flags.String("o", "", "the prefix of the output files, default is the input filename")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if *prefix == "" {
+		if flags.Arg(0) == "-" {
+			return fmt.Errorf("an output prefix is required when reading from stdin")
+		}
+		*prefix = strings.TrimSuffix(flags.Arg(0), ".log")
+	}
+
+	var keyOf func(cabrillo.QSO) string
+	switch strings.ToLower(*by) {
+	case "transmitter":
+		keyOf = func(qso cabrillo.QSO) string { return strconv.Itoa(qso.Transmitter) }
+	case "band":
+		keyOf = func(qso cabrillo.QSO) string { return string(qso.Frequency.ToBand()) }
+	case "mode":
+		keyOf = func(qso cabrillo.QSO) string { return string(qso.Mode) }
+	default:
+		return fmt.Errorf("cannot split by %q", *by)
+	}
+
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	parts := make(map[string]*cabrillo.Log)
+	var keys []string
+	part := func(key string) *cabrillo.Log {
+		result, ok := parts[key]
+		if !ok {
+			copied := *l
+			copied.QSOData = []cabrillo.QSO{}
+			copied.IgnoredQSOs = []cabrillo.QSO{}
+			result = &copied
+			parts[key] = result
+			keys = append(keys, key)
+		}
+		return result
+	}
+	for _, qso := range l.QSOData {
+		p := part(keyOf(qso))
+		p.QSOData = append(p.QSOData, qso)
+	}
+	for _, qso := range l.IgnoredQSOs {
+		p := part(keyOf(qso))
+		p.IgnoredQSOs = append(p.IgnoredQSOs, qso)
+	}
+
+	for _, key := range keys {
+		filename := fmt.Sprintf("%s-%s.log", *prefix, strings.ToLower(key))
+		err = writeLog(filename, parts[key])
+		if err != nil {
+			return err
+		}
+		fmt.Println(filename)
+	}
+	return nil
+}
+
+func runScore(args []string) error {
+	flags := newFlagSet("score", "<file>")
+	asJSON := flags.Bool("json", false, "write the result as JSON")
+	var rules cabrillo.ScoringRules
+	flags.IntVar(&rules.PointsPerQSO, "points", 1, "the number of points per QSO")
+	flags.IntVar(&rules.MultiplierElement, "mult", 0, "the 1-based position of the multiplier in the received exchange, 0 means no multipliers")
+	flags.BoolVar(&rules.MultipliersPerBand, "mult-per-band", false, "count the multipliers separately on each band")
+	flags.BoolVar(&rules.DupesPerMode, "per-mode", false, "allow to work the same station on the same band in different modes")
+	summary := flags.Bool("summary", false, "write a summary for posting the score on 3830scores.com")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	score := rules.Score(l.QSOData)
+	if *asJSON {
+		return writeJSON(os.Stdout, score)
+	}
+	if *summary {
+		return cabrillo.WriteSummary(os.Stdout, l, score)
+	}
+	fmt.Printf("%-6s %-4s %6s %6s %6s %6s\n", "Band", "Mode", "QSOs", "Dupes", "Points", "Mults")
+	for _, entry := range score.Breakdown {
+		fmt.Printf("%-6s %-4s %6d %6d %6d %6d\n", entry.Band, entry.Mode, entry.QSOs, entry.Dupes, entry.Points, entry.Multipliers)
+	}
+	fmt.Printf("%-11s %6d %6d %6d %6d\n", "Total", score.QSOs, score.Dupes, score.Points, score.Multipliers)
+	fmt.Printf("Score: %d\n", score.Total)
+	if l.ClaimedScore != 0 && l.ClaimedScore != score.Total {
+		fmt.Printf("Claimed score: %d\n", l.ClaimedScore)
+	}
+	return nil
+}
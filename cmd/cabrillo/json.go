@@ -0,0 +1,105 @@
+package main
+
+import (
+	"github.com/ftl/cabrillo"
+)
+
+// The JSON representation of a log uses plain strings for callsigns and locators.
+
+type jsonLog struct {
+	CabrilloVersion string            `json:"cabrillo_version"`
+	Callsign        string            `json:"callsign"`
+	Contest         string            `json:"contest"`
+	Category        cabrillo.Category `json:"category"`
+	Certificate     bool              `json:"certificate"`
+	ClaimedScore    int               `json:"claimed_score"`
+	Club            string            `json:"club,omitempty"`
+	CreatedBy       string            `json:"created_by,omitempty"`
+	Email           string            `json:"email,omitempty"`
+	GridLocator     string            `json:"grid_locator,omitempty"`
+	Location        string            `json:"location,omitempty"`
+	Name            string            `json:"name,omitempty"`
+	Address         cabrillo.Address  `json:"address"`
+	Operators       []string          `json:"operators"`
+	Host            string            `json:"host,omitempty"`
+	Offtime         *cabrillo.Offtime `json:"offtime,omitempty"`
+	Soapbox         string            `json:"soapbox,omitempty"`
+	Custom          []jsonCustomValue `json:"custom,omitempty"`
+	QSOData         []jsonQSO         `json:"qso_data"`
+	IgnoredQSOs     []jsonQSO         `json:"ignored_qsos"`
+}
+
+type jsonCustomValue struct {
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+type jsonQSO struct {
+	Frequency   string      `json:"frequency"`
+	Band        string      `json:"band"`
+	Mode        string      `json:"mode"`
+	Timestamp   string      `json:"timestamp"`
+	Sent        jsonQSOInfo `json:"sent"`
+	Received    jsonQSOInfo `json:"received"`
+	Transmitter int         `json:"transmitter"`
+}
+
+type jsonQSOInfo struct {
+	Call     string   `json:"call"`
+	Exchange []string `json:"exchange"`
+}
+
+func toJSONLog(l *cabrillo.Log) jsonLog {
+	result := jsonLog{
+		CabrilloVersion: l.CabrilloVersion,
+		Callsign:        l.Callsign.String(),
+		Contest:         string(l.Contest),
+		Category:        l.Category,
+		Certificate:     l.Certificate,
+		ClaimedScore:    l.ClaimedScore,
+		Club:            l.Club,
+		CreatedBy:       l.CreatedBy,
+		Email:           l.Email,
+		Location:        l.Location,
+		Name:            l.Name,
+		Address:         l.Address,
+		Operators:       make([]string, 0, len(l.Operators)),
+		Host:            l.Host.String(),
+		Soapbox:         l.Soapbox,
+		QSOData:         toJSONQSOs(l.QSOData),
+		IgnoredQSOs:     toJSONQSOs(l.IgnoredQSOs),
+	}
+	if !l.GridLocator.IsZero() {
+		result.GridLocator = l.GridLocator.String()
+	}
+	for _, op := range l.Operators {
+		result.Operators = append(result.Operators, op.String())
+	}
+	if !l.Offtime.Begin.IsZero() {
+		result.Offtime = &l.Offtime
+	}
+	for _, value := range l.Custom {
+		result.Custom = append(result.Custom, jsonCustomValue{Tag: string(value.Tag), Value: value.Value})
+	}
+	return result
+}
+
+func toJSONQSOs(qsos []cabrillo.QSO) []jsonQSO {
+	result := make([]jsonQSO, 0, len(qsos))
+	for _, qso := range qsos {
+		result = append(result, toJSONQSO(qso))
+	}
+	return result
+}
+
+func toJSONQSO(qso cabrillo.QSO) jsonQSO {
+	return jsonQSO{
+		Frequency:   string(qso.Frequency),
+		Band:        string(qso.Frequency.ToBand()),
+		Mode:        string(qso.Mode),
+		Timestamp:   qso.Timestamp.UTC().Format(cabrillo.TimestampLayout),
+		Sent:        jsonQSOInfo{Call: qso.Sent.Call.String(), Exchange: qso.Sent.Exchange},
+		Received:    jsonQSOInfo{Call: qso.Received.Call.String(), Exchange: qso.Received.Exchange},
+		Transmitter: qso.Transmitter,
+	}
+}
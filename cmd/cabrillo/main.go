@@ -0,0 +1,173 @@
+// Command cabrillo provides tools to check, format, convert and analyze Cabrillo log files.
+//
+// Usage:
+//
+//	cabrillo <command> [flags] [files]
+//
+// Use "-" as filename to read from stdin. All commands that produce reports support the -json flag
+// for machine-readable output. The command exits with status 1 if an error or a problem was found
+// and with status 2 if it was used wrongly.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ftl/cabrillo"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{"validate", "check the given logs against the Cabrillo specification", runValidate},
+	{"fmt", "format a log in a canonical way", runFmt},
+	{"convert", "convert a log into another format (cabrillo, adif, csv, json)", runConvert},
+	{"stats", "show statistics about the QSO data of a log", runStats},
+	{"dupes", "list the dupes in a log", runDupes},
+	{"order", "list the QSOs that are not in chronological order", runOrder},
+	{"merge", "merge several logs into one", runMerge},
+	{"redact", "remove personal data from a log before publishing it", runRedact},
+	{"sign", "sign a log with an ed25519 key", runSign},
+	{"verify", "verify the signature of a log", runVerify},
+	{"shift", "correct the timestamps of a log or estimate its clock offset", runShift},
+	{"recover", "salvage truncated logs and split concatenated logs", runRecover},
+	{"split", "split a log by transmitter, band or mode", runSplit},
+	{"score", "calculate the score of a log using a generic scoring scheme", runScore},
+}
+
+// errUsage indicates that the command was used wrongly.
+var errUsage = errors.New("usage")
+
+// errProblemsFound indicates that the command found problems that were already reported.
+var errProblemsFound = errors.New("problems found")
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+			return 2
+		case errors.Is(err, errProblemsFound):
+			return 1
+		default:
+			fmt.Fprintf(os.Stderr, "cabrillo %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+	if args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+	}
+	usage()
+	return 2
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cabrillo <command> [flags] [files]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+}
+
+func newFlagSet(name string, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: cabrillo %s [flags] %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func parseFlags(flags *flag.FlagSet, args []string, minFiles int, maxFiles int) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() < minFiles || (maxFiles > 0 && flags.NArg() > maxFiles) {
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func openInput(filename string) (io.ReadCloser, error) {
+	if filename == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(filename)
+}
+
+func readLog(filename string) (*cabrillo.Log, error) {
+	file, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result, err := cabrillo.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return result, nil
+}
+
+// createOutput returns stdout if the filename is empty or "-".
+func createOutput(filename string) (io.WriteCloser, error) {
+	if filename == "" || filename == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(filename)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func writeLog(filename string, l *cabrillo.Log) error {
+	return formatLog(filename, l, cabrillo.Formatter{})
+}
+
+func formatLog(filename string, l *cabrillo.Log, formatter cabrillo.Formatter) error {
+	out, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	err = formatter.FormatLog(out, l)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLog = `START-OF-LOG: 3.0
+CALLSIGN: DL1ABC
+CONTEST: CQ-WPX-CW
+CATEGORY-OPERATOR: SINGLE-OP
+CATEGORY-BAND: ALL
+CATEGORY-MODE: CW
+CATEGORY-POWER: HIGH
+CATEGORY-TRANSMITTER: ONE
+QSO: 14025 CW 2024-05-25 0000 DL1ABC 599 1 W1AW 599 5
+QSO: 14026 CW 2024-05-25 0001 DL1ABC 599 2 K1AR 599 7
+QSO: 7025 CW 2024-05-25 0002 DL1ABC 599 3 W1AW 599 9
+END-OF-LOG:
+`
+
+const testLogWithProblems = `START-OF-LOG: 3.0
+CALLSIGN: DL1ABC
+CONTEST: CQ-WPX-CW
+CATEGORY-OPERATOR: SINGLE-OP
+CATEGORY-BAND: ALL
+CATEGORY-MODE: CW
+CATEGORY-POWER: HIGH
+CATEGORY-TRANSMITTER: ONE
+QSO: 14025 CW 2024-05-25 0001 DL1ABC 599 1 W1AW 599 5
+QSO: 14026 CW 2024-05-25 0000 DL1ABC 599 2 K1AR 599 7
+QSO: 14027 CW 2024-05-25 0002 DL1ABC 599 3 W1AW 599 5
+END-OF-LOG:
+`
+
+// runCommand runs the command line tool with the given arguments and returns the exit status and the output on stdout.
+func runCommand(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	require.NoError(t, err)
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() {
+		os.Stdout, os.Stderr = originalStdout, originalStderr
+		stdout.Close()
+		stderr.Close()
+	}()
+
+	status := run(args)
+
+	output, err := os.ReadFile(stdout.Name())
+	require.NoError(t, err)
+	return status, string(output)
+}
+
+func writeTestLog(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "test.log")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	return filename
+}
+
+func TestRun_ExitStatus(t *testing.T) {
+	valid := writeTestLog(t, testLog)
+	withProblems := writeTestLog(t, testLogWithProblems)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "no command", args: []string{}, expected: 2},
+		{name: "unknown command", args: []string{"unknown"}, expected: 2},
+		{name: "missing file argument", args: []string{"dupes"}, expected: 2},
+		{name: "missing file", args: []string{"dupes", filepath.Join(t.TempDir(), "missing.log")}, expected: 1},
+		{name: "validate valid", args: []string{"validate", valid}, expected: 0},
+		{name: "dupes without dupes", args: []string{"dupes", valid}, expected: 0},
+		{name: "dupes with dupes", args: []string{"dupes", withProblems}, expected: 1},
+		{name: "dupes with dupes as JSON", args: []string{"dupes", "-json", withProblems}, expected: 1},
+		{name: "order in order", args: []string{"order", valid}, expected: 0},
+		{name: "order out of order", args: []string{"order", withProblems}, expected: 1},
+		{name: "order out of order as JSON", args: []string{"order", "-json", withProblems}, expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := runCommand(t, tt.args...)
+
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestRun_Reports(t *testing.T) {
+	withProblems := writeTestLog(t, testLogWithProblems)
+
+	_, output := runCommand(t, "dupes", withProblems)
+	assert.Equal(t, "QSO 3 is a dupe of QSO 1: 2024-05-25 0002 20M CW W1AW\n", output)
+
+	_, output = runCommand(t, "order", withProblems)
+	assert.Equal(t, "QSO 2 is earlier than QSO 1: 2024-05-25 0000 20M CW K1AR\n", output)
+}
+
+func TestRun_Fmt(t *testing.T) {
+	filename := writeTestLog(t, testLogWithProblems)
+
+	status, output := runCommand(t, "fmt", "-sort", filename)
+	require.Equal(t, 0, status)
+
+	assert.Contains(t, output, "QSO: 14026 CW 2024-05-25 0000 DL1ABC 599 2 K1AR 599 7\nQSO: 14025 CW 2024-05-25 0001 DL1ABC 599 1 W1AW 599 5\n")
+}
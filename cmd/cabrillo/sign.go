@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ftl/cabrillo"
+)
+
+func runSign(args []string) error {
+	flags := newFlagSet("sign", "<file>")
+	keyFile := flags.String("key", "", "the PEM file with the ed25519 private key (PKCS #8)")
+	output := flags.String("o", "", "the output file, default is stdout")
+	err := parseFlags(flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	key, err := readPEMKey(*keyFile, x509.ParsePKCS8PrivateKey)
+	if err != nil {
+		return err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return fmt.Errorf("%s: not an ed25519 private key", *keyFile)
+	}
+	l, err := readLog(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	err = cabrillo.Sign(l, privateKey)
+	if err != nil {
+		return err
+	}
+	return writeLog(*output, l)
+}
+
+func runVerify(args []string) error {
+	flags := newFlagSet("verify", "<file>...")
+	keyFile := flags.String("key", "", "the PEM file with the ed25519 public key (PKIX)")
+	err := parseFlags(flags, args, 1, 0)
+	if err != nil {
+		return err
+	}
+	key, err := readPEMKey(*keyFile, x509.ParsePKIXPublicKey)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("%s: not an ed25519 public key", *keyFile)
+	}
+
+	result := error(nil)
+	for _, filename := range flags.Args() {
+		l, err := readLog(filename)
+		if err != nil {
+			return err
+		}
+		err = cabrillo.Verify(l, publicKey)
+		switch {
+		case err == nil:
+			fmt.Printf("%s: valid signature\n", filename)
+		case errors.Is(err, cabrillo.ErrNotSigned), errors.Is(err, cabrillo.ErrInvalidSignature):
+			fmt.Printf("%s: %v\n", filename, err)
+			result = errProblemsFound
+		default:
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return result
+}
+
+func readPEMKey(filename string, parse func([]byte) (any, error)) (any, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("no key file given, use -key")
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", filename)
+	}
+	result, err := parse(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package cabrillo
+
+import "strings"
+
+// CheckSentData checks the sent data of all QSOs against the header and against each other. It reports QSOs
+// whose sent call differs from the callsign in the header, whose constant sent exchange elements differ from the
+// value used in most QSOs, and whose sent serial numbers go backwards, skip or repeat. The serial numbers are
+// checked separately for each transmitter.
+func CheckSentData(l *Log, definition ExchangeDefinition) []Problem {
+	var result []Problem
+
+	if l.Callsign.String() != "" {
+		headerCall := formatCallsign(l.Callsign)
+		for i, qso := range l.QSOData {
+			sentCall := formatCallsign(qso.Sent.Call)
+			if sentCall != headerCall {
+				result = append(result, qsoProblem(i, "the sent call %s differs from the callsign %s", sentCall, headerCall))
+			}
+		}
+	}
+
+	for index, field := range definition {
+		if !field.IsConstant() {
+			continue
+		}
+		expected := majorityValue(l.QSOData, func(qso QSO) string { return exchangeElement(qso.Sent.Exchange, index+1) })
+		for i, qso := range l.QSOData {
+			value := exchangeElement(qso.Sent.Exchange, index+1)
+			if value != expected {
+				result = append(result, qsoProblem(i, "the sent %s %q differs from %q", field, value, expected))
+			}
+		}
+	}
+
+	if definition.Index(SerialField) != -1 {
+		lastSerials := make(map[int]int)
+		for i, qso := range l.QSOData {
+			serial, err := qso.Sent.Serial(definition)
+			if err != nil {
+				result = append(result, qsoProblem(i, "invalid sent serial number: %v", err))
+				continue
+			}
+			last, found := lastSerials[qso.Transmitter]
+			lastSerials[qso.Transmitter] = serial
+			if !found {
+				continue
+			}
+			switch {
+			case serial == last:
+				result = append(result, qsoProblem(i, "the sent serial number %d is repeated", serial))
+			case serial < last:
+				result = append(result, qsoProblem(i, "the sent serial number %d goes backwards from %d", serial, last))
+			case serial > last+1:
+				result = append(result, qsoProblem(i, "the sent serial number %d skips from %d", serial, last))
+			}
+		}
+	}
+
+	return result
+}
+
+// majorityValue returns the value that occurs most often. If several values occur equally often,
+// the one that occurs first is returned.
+func majorityValue(qsos []QSO, valueOf func(QSO) string) string {
+	counts := make(map[string]int)
+	values := make([]string, 0, len(qsos))
+	for _, qso := range qsos {
+		value := valueOf(qso)
+		if counts[value] == 0 {
+			values = append(values, value)
+		}
+		counts[value]++
+	}
+	var result string
+	maxCount := 0
+	for _, value := range values {
+		if counts[value] > maxCount {
+			result = value
+			maxCount = counts[value]
+		}
+	}
+	return result
+}
+
+// ReceivedInconsistency describes a station whose received exchange differs between several QSOs.
+type ReceivedInconsistency struct {
+	Call  string
+	Field ExchangeField
+	// Suggestion is the value that was received in most QSOs with this station.
+	Suggestion string
+	// QSOs contains the indices of all QSOs with a value that differs from the suggestion.
+	QSOs []int
+	// Values contains the differing values in the same order as QSOs.
+	Values []string
+}
+
+// CheckReceivedData groups the given QSOs by the received call and reports all stations whose constant
+// exchange elements (e.g. zone, state, name, section) differ between the QSOs. The most common value is
+// suggested as the correct one.
+func CheckReceivedData(qsos []QSO, definition ExchangeDefinition) []ReceivedInconsistency {
+	var calls []string
+	qsosByCall := make(map[string][]int)
+	for i, qso := range qsos {
+		call := formatCallsign(qso.Received.Call)
+		if _, found := qsosByCall[call]; !found {
+			calls = append(calls, call)
+		}
+		qsosByCall[call] = append(qsosByCall[call], i)
+	}
+
+	var result []ReceivedInconsistency
+	for _, call := range calls {
+		indices := qsosByCall[call]
+		if len(indices) < 2 {
+			continue
+		}
+		stationQSOs := make([]QSO, len(indices))
+		for i, index := range indices {
+			stationQSOs[i] = qsos[index]
+		}
+
+		for fieldIndex, field := range definition {
+			if !field.IsConstant() {
+				continue
+			}
+			valueOf := func(qso QSO) string {
+				return strings.ToUpper(exchangeElement(qso.Received.Exchange, fieldIndex+1))
+			}
+			suggestion := majorityValue(stationQSOs, valueOf)
+			inconsistency := ReceivedInconsistency{Call: call, Field: field, Suggestion: suggestion}
+			for i, qso := range stationQSOs {
+				value := valueOf(qso)
+				if value == suggestion {
+					continue
+				}
+				inconsistency.QSOs = append(inconsistency.QSOs, indices[i])
+				inconsistency.Values = append(inconsistency.Values, value)
+			}
+			if len(inconsistency.QSOs) > 0 {
+				result = append(result, inconsistency)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package cabrillo
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+)
+
+func sentTestQSO(sentCall string, transmitter int, exchange ...string) QSO {
+	return QSO{
+		Frequency:   "14025",
+		Mode:        QSOModeCW,
+		Sent:        QSOInfo{Call: callsign.MustParse(sentCall), Exchange: exchange},
+		Received:    QSOInfo{Call: callsign.MustParse("W1AW"), Exchange: []string{"599", "1"}},
+		Transmitter: transmitter,
+	}
+}
+
+func TestCheckSentData(t *testing.T) {
+	log := NewLog()
+	log.Callsign = callsign.MustParse("DL1ABC")
+	log.QSOData = []QSO{
+		sentTestQSO("DL1ABC", 0, "599", "14", "1"),
+		sentTestQSO("DL1ABC", 0, "599", "14", "2"),
+		sentTestQSO("DL1ABD", 0, "599", "14", "3"),
+		sentTestQSO("DL1ABC", 0, "599", "15", "4"),
+		sentTestQSO("DL1ABC", 0, "599", "14", "4"),
+		sentTestQSO("DL1ABC", 1, "599", "14", "1"),
+		sentTestQSO("DL1ABC", 0, "599", "14", "3"),
+		sentTestQSO("DL1ABC", 1, "599", "14", "3"),
+	}
+	definition := ExchangeDefinition{RSTField, CQZoneField, SerialField}
+
+	actual := CheckSentData(log, definition)
+
+	assert.Equal(t, []Problem{
+		{Tag: QSOTag, QSO: 2, Message: "the sent call DL1ABD differs from the callsign DL1ABC"},
+		{Tag: QSOTag, QSO: 3, Message: `the sent CQ-ZONE "15" differs from "14"`},
+		{Tag: QSOTag, QSO: 4, Message: "the sent serial number 4 is repeated"},
+		{Tag: QSOTag, QSO: 6, Message: "the sent serial number 3 goes backwards from 4"},
+		{Tag: QSOTag, QSO: 7, Message: "the sent serial number 3 skips from 1"},
+	}, actual)
+}
+
+func receivedTestQSO(frequency QSOFrequency, call string, exchange ...string) QSO {
+	return QSO{
+		Frequency: frequency,
+		Mode:      QSOModeCW,
+		Sent:      QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}},
+		Received:  QSOInfo{Call: callsign.MustParse(call), Exchange: exchange},
+	}
+}
+
+func TestCheckReceivedData(t *testing.T) {
+	qsos := []QSO{
+		receivedTestQSO("14025", "W1AW", "599", "5"),
+		receivedTestQSO("14026", "JA1ABC", "599", "25"),
+		receivedTestQSO("7025", "W1AW", "599", "5"),
+		receivedTestQSO("3525", "W1AW", "579", "4"),
+		receivedTestQSO("7026", "JA1ABC", "599", "25"),
+		receivedTestQSO("21025", "W1AW", "599", "5"),
+		receivedTestQSO("21026", "K1AR", "599", "5"),
+	}
+
+	actual := CheckReceivedData(qsos, CQWWExchange)
+
+	assert.Equal(t, []ReceivedInconsistency{
+		{Call: "W1AW", Field: CQZoneField, Suggestion: "5", QSOs: []int{3}, Values: []string{"4"}},
+	}, actual)
+}
+
+func TestMajorityValue(t *testing.T) {
+	tests := []struct {
+		desc     string
+		values   []string
+		expected string
+	}{
+		{desc: "no values", expected: ""},
+		{desc: "majority", values: []string{"14", "15", "14"}, expected: "14"},
+		{desc: "tie goes to the first value", values: []string{"14", "15", "15", "14"}, expected: "14"},
+		{desc: "tie with an empty value", values: []string{"14", ""}, expected: "14"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			qsos := make([]QSO, len(tc.values))
+			for i, value := range tc.values {
+				qsos[i] = sentTestQSO("DL1ABC", 0, "599", value)
+			}
+
+			actual := majorityValue(qsos, func(qso QSO) string { return qso.Sent.Exchange[1] })
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
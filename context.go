@@ -0,0 +1,34 @@
+package cabrillo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// contextWriter checks the context before each write. The log writer writes complete lines,
+// so the context is checked between lines. The line number is derived from the line breaks
+// that were written, since one write may contain several lines.
+type contextWriter struct {
+	ctx   context.Context
+	w     io.Writer
+	lines int
+}
+
+func newContextWriter(ctx context.Context, w io.Writer) io.Writer {
+	if ctx.Done() == nil {
+		return w
+	}
+	return &contextWriter{ctx: ctx, w: w}
+}
+
+func (w *contextWriter) Write(p []byte) (int, error) {
+	err := w.ctx.Err()
+	if err != nil {
+		return 0, fmt.Errorf("line %d: %w", w.lines+1, err)
+	}
+	n, err := w.w.Write(p)
+	w.lines += bytes.Count(p[:n], []byte("\n"))
+	return n, err
+}
@@ -0,0 +1,102 @@
+package cabrillo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadContext(t *testing.T) {
+	input := "START-OF-LOG: 3.0\nX-CANCEL: now\nCALLSIGN: DL1ABC\nEND-OF-LOG:\n"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader := NewReader()
+	reader.RegisterTag("X-CANCEL", func(*Log, string) error {
+		cancel()
+		return nil
+	})
+
+	_, err := reader.ReadContext(ctx, strings.NewReader(input))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "line 3: context canceled")
+}
+
+func TestReadContext_NotCanceled(t *testing.T) {
+	f, err := os.Open("testdata/cqww.v3.cabrillo")
+	require.NoError(t, err)
+	defer f.Close()
+
+	log, err := ReadContext(context.Background(), f)
+	require.NoError(t, err)
+
+	assert.Len(t, log.QSOData, 5)
+}
+
+// cancelingWriter cancels the context after the given number of writes.
+type cancelingWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+	writes int
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	w.writes--
+	if w.writes == 0 {
+		w.cancel()
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriteContext(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Custom.Add("X-A", "a")
+	log.Custom.Add("X-B", "b")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &cancelingWriter{cancel: cancel, writes: 2}
+
+	err := NewWriter().WriteWithTagsContext(ctx, out, log, false, true, "X-A", "X-B")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "line 3: context canceled")
+	assert.Equal(t, "START-OF-LOG: 3.0\nX-A: a\n", out.String())
+}
+
+func TestWriteContext_NotCanceled(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	expected := &bytes.Buffer{}
+	require.NoError(t, Write(expected, log, false))
+	actual := &bytes.Buffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := WriteContext(ctx, actual, log, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.String(), actual.String())
+}
+
+func TestContextWriter_CountsLineBreaks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &bytes.Buffer{}
+	w := newContextWriter(ctx, out)
+
+	_, err := io.WriteString(w, "START-OF-LOG: 3.0\nCALLSIGN: DL1ABC\n")
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "CONTEST: ")
+	require.NoError(t, err)
+	cancel()
+	_, err = io.WriteString(w, "CQ-WW-CW\n")
+
+	assert.EqualError(t, err, "line 3: context canceled")
+}
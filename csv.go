@@ -0,0 +1,433 @@
+package cabrillo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ftl/hamradio/callsign"
+)
+
+const (
+	CSVDateLayout = "2006-01-02"
+	CSVTimeLayout = "1504"
+)
+
+// CSVField identifies the QSO field that is stored in a CSV column.
+type CSVField string
+
+const (
+	CSVFrequency        CSVField = "frequency"
+	CSVBand             CSVField = "band"
+	CSVMode             CSVField = "mode"
+	CSVDate             CSVField = "date"
+	CSVTime             CSVField = "time"
+	CSVSentCall         CSVField = "sent_call"
+	CSVSentExchange     CSVField = "sent_exch"
+	CSVReceivedCall     CSVField = "rcvd_call"
+	CSVReceivedExchange CSVField = "rcvd_exch"
+	CSVTransmitter      CSVField = "transmitter"
+)
+
+// CSVColumn describes the content of a CSV column. Index is the 1-based
+// position within the exchange and only used for the exchange fields.
+type CSVColumn struct {
+	Field CSVField
+	Index int
+}
+
+func (c CSVColumn) String() string {
+	if c.Index > 0 {
+		return fmt.Sprintf("%s_%d", c.Field, c.Index)
+	}
+	return string(c.Field)
+}
+
+func ParseCSVColumn(s string) (CSVColumn, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch CSVField(s) {
+	case CSVFrequency, CSVBand, CSVMode, CSVDate, CSVTime, CSVSentCall, CSVReceivedCall, CSVTransmitter:
+		return CSVColumn{Field: CSVField(s)}, nil
+	}
+	for _, field := range []CSVField{CSVSentExchange, CSVReceivedExchange} {
+		indexStr, found := strings.CutPrefix(s, string(field)+"_")
+		if !found {
+			continue
+		}
+		index, err := strconv.Atoi(indexStr)
+		if err != nil || index < 1 {
+			return CSVColumn{}, fmt.Errorf("%s is not a valid exchange column", s)
+		}
+		return CSVColumn{Field: field, Index: index}, nil
+	}
+	return CSVColumn{}, fmt.Errorf("%s is not a valid CSV column", s)
+}
+
+// CSVMapping maps the names in the CSV header row to the QSO fields. The names are
+// compared case-insensitive. Columns that are not contained in the mapping are ignored.
+type CSVMapping map[string]CSVColumn
+
+// ParseCSVMapping parses a mapping description of the form "<header>=<column>, ...",
+// e.g. "Freq=frequency, Call=rcvd_call, Zone=rcvd_exch_2".
+func ParseCSVMapping(s string) (CSVMapping, error) {
+	result := make(CSVMapping)
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, columnStr, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("%s is not a valid CSV mapping entry", entry)
+		}
+		column, err := ParseCSVColumn(columnStr)
+		if err != nil {
+			return nil, err
+		}
+		result[strings.ToLower(strings.TrimSpace(name))] = column
+	}
+	return result, nil
+}
+
+func (m CSVMapping) column(name string) (CSVColumn, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if m == nil {
+		column, err := ParseCSVColumn(name)
+		return column, err == nil
+	}
+	column, ok := m[name]
+	return column, ok
+}
+
+// CSVRowError describes an error in a particular row of the CSV data. Row is 1-based and includes the header row.
+type CSVRowError struct {
+	Row int
+	Err error
+}
+
+func (e CSVRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// WriteCSV writes the given QSOs as CSV, including a header row. The header uses the
+// column names that are understood by ReadCSV without an explicit mapping.
+func WriteCSV(w io.Writer, qsos []QSO) error {
+	sentExchangeLength := 0
+	receivedExchangeLength := 0
+	for _, qso := range qsos {
+		sentExchangeLength = max(sentExchangeLength, len(qso.Sent.Exchange))
+		receivedExchangeLength = max(receivedExchangeLength, len(qso.Received.Exchange))
+	}
+
+	columns := make([]CSVColumn, 0, 8+sentExchangeLength+receivedExchangeLength)
+	columns = append(columns,
+		CSVColumn{Field: CSVFrequency}, CSVColumn{Field: CSVBand}, CSVColumn{Field: CSVMode},
+		CSVColumn{Field: CSVDate}, CSVColumn{Field: CSVTime}, CSVColumn{Field: CSVSentCall},
+	)
+	for i := range sentExchangeLength {
+		columns = append(columns, CSVColumn{Field: CSVSentExchange, Index: i + 1})
+	}
+	columns = append(columns, CSVColumn{Field: CSVReceivedCall})
+	for i := range receivedExchangeLength {
+		columns = append(columns, CSVColumn{Field: CSVReceivedExchange, Index: i + 1})
+	}
+	columns = append(columns, CSVColumn{Field: CSVTransmitter})
+
+	csvWriter := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.String()
+	}
+	err := csvWriter.Write(record)
+	if err != nil {
+		return err
+	}
+
+	for _, qso := range qsos {
+		for i, column := range columns {
+			record[i] = csvValue(qso, column)
+		}
+		err = csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func csvValue(qso QSO, column CSVColumn) string {
+	switch column.Field {
+	case CSVFrequency:
+		return string(qso.Frequency)
+	case CSVBand:
+		return string(qso.Frequency.ToBand())
+	case CSVMode:
+		return string(qso.Mode)
+	case CSVDate:
+		return qso.Timestamp.UTC().Format(CSVDateLayout)
+	case CSVTime:
+		return qso.Timestamp.UTC().Format(CSVTimeLayout)
+	case CSVSentCall:
+		return qso.Sent.Call.String()
+	case CSVSentExchange:
+		return exchangeElement(qso.Sent.Exchange, column.Index)
+	case CSVReceivedCall:
+		return qso.Received.Call.String()
+	case CSVReceivedExchange:
+		return exchangeElement(qso.Received.Exchange, column.Index)
+	case CSVTransmitter:
+		return strconv.Itoa(qso.Transmitter)
+	default:
+		return ""
+	}
+}
+
+func exchangeElement(exchange []string, index int) string {
+	if index < 1 || index > len(exchange) {
+		return ""
+	}
+	return exchange[index-1]
+}
+
+// ReadCSV reads QSOs from CSV data. The first row must be a header row. Its column names are
+// resolved using the given mapping. If the mapping is nil, the column names written by WriteCSV
+// are expected.
+//
+// Rows that cannot be converted into a QSO are skipped and reported as CSVRowError. The returned
+// error is only non-nil if the CSV data cannot be read at all.
+func ReadCSV(r io.Reader, mapping CSVMapping) ([]QSO, []CSVRowError, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("no CSV header found")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make([]CSVColumn, len(header))
+	available := make(map[CSVField]bool)
+	for i, name := range header {
+		column, ok := mapping.column(name)
+		if !ok {
+			continue
+		}
+		columns[i] = column
+		available[column.Field] = true
+	}
+	if !available[CSVFrequency] && !available[CSVBand] {
+		return nil, nil, fmt.Errorf("no CSV column for %s or %s", CSVFrequency, CSVBand)
+	}
+	for _, field := range []CSVField{CSVMode, CSVDate, CSVTime, CSVSentCall, CSVReceivedCall} {
+		if !available[field] {
+			return nil, nil, fmt.Errorf("no CSV column for %s", field)
+		}
+	}
+
+	var qsos []QSO
+	var rowErrors []CSVRowError
+	row := 1
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		row++
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Err: err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		qso, err := parseCSVRecord(record, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Err: err})
+			continue
+		}
+		qsos = append(qsos, qso)
+	}
+
+	return qsos, rowErrors, nil
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseCSVRecord(record []string, columns []CSVColumn) (QSO, error) {
+	var result QSO
+	var band, date, timeOfDay string
+	var err error
+	for i, value := range record {
+		if i >= len(columns) {
+			break
+		}
+		column := columns[i]
+		value = strings.TrimSpace(value)
+		switch column.Field {
+		case CSVFrequency:
+			result.Frequency = QSOFrequency(strings.ToUpper(value))
+		case CSVBand:
+			band = strings.ToUpper(value)
+		case CSVMode:
+			result.Mode = QSOMode(strings.ToUpper(value))
+		case CSVDate:
+			date = value
+		case CSVTime:
+			// spreadsheets tend to drop leading zeros or add a colon
+			timeOfDay = strings.ReplaceAll(value, ":", "")
+			if len(timeOfDay) < 4 {
+				timeOfDay = strings.Repeat("0", 4-len(timeOfDay)) + timeOfDay
+			}
+		case CSVSentCall:
+			result.Sent.Call, err = callsign.Parse(value)
+			if err != nil {
+				return QSO{}, fmt.Errorf("invalid sent call: %w", err)
+			}
+		case CSVSentExchange:
+			result.Sent.Exchange = setExchangeElement(result.Sent.Exchange, column.Index, value)
+		case CSVReceivedCall:
+			result.Received.Call, err = callsign.Parse(value)
+			if err != nil {
+				return QSO{}, fmt.Errorf("invalid received call: %w", err)
+			}
+		case CSVReceivedExchange:
+			result.Received.Exchange = setExchangeElement(result.Received.Exchange, column.Index, value)
+		case CSVTransmitter:
+			if value == "" {
+				continue
+			}
+			result.Transmitter, err = strconv.Atoi(value)
+			if err != nil {
+				return QSO{}, fmt.Errorf("invalid transmitter: %w", err)
+			}
+		}
+	}
+
+	result.Frequency, err = csvFrequency(result.Frequency, CategoryBand(band))
+	if err != nil {
+		return QSO{}, err
+	}
+	if result.Mode == "" {
+		return QSO{}, fmt.Errorf("missing mode")
+	}
+	result.Timestamp, err = ParseTimestamp(date + " " + timeOfDay)
+	if err != nil {
+		return QSO{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	if len(result.Sent.Exchange) == 0 || len(result.Received.Exchange) == 0 {
+		return QSO{}, fmt.Errorf("missing exchange")
+	}
+	if len(result.Sent.Exchange) != len(result.Received.Exchange) {
+		return QSO{}, fmt.Errorf("sent and received exchange differ in length: %d != %d", len(result.Sent.Exchange), len(result.Received.Exchange))
+	}
+	// an empty element would shift all following columns of the QSO line
+	for i := range result.Sent.Exchange {
+		if result.Sent.Exchange[i] == "" {
+			return QSO{}, fmt.Errorf("missing sent exchange element %d", i+1)
+		}
+		if result.Received.Exchange[i] == "" {
+			return QSO{}, fmt.Errorf("missing received exchange element %d", i+1)
+		}
+	}
+
+	return result, nil
+}
+
+// csvBandEdges are used as frequency if only the band of a QSO below 50 MHz is known. The Cabrillo QSO
+// line needs a frequency in kHz for these bands.
+var csvBandEdges = map[CategoryBand]QSOFrequency{
+	Band160m: "1800",
+	Band80m:  "3500",
+	Band40m:  "7000",
+	Band20m:  "14000",
+	Band15m:  "21000",
+	Band10m:  "28000",
+}
+
+// csvBandDesignators map the bands from 50 MHz upwards to the band designators of the QSO line.
+var csvBandDesignators = map[CategoryBand]QSOFrequency{
+	Band6m:    Frequency50MHz,
+	Band4m:    Frequency70MHz,
+	Band2m:    Frequency144MHz,
+	Band222:   Frequency222MHz,
+	Band432:   Frequency432MHz,
+	Band902:   Frequency902MHz,
+	Band1_2G:  Frequency1_2GHz,
+	Band2_3G:  Frequency2_3GHz,
+	Band3_4G:  Frequency3_4GHz,
+	Band5_6G:  Frequency5_7GHz,
+	Band10G:   Frequency10GHz,
+	Band24G:   Frequency24GHz,
+	Band47G:   Frequency47GHz,
+	Band75G:   Frequency75GHz,
+	Band122G:  Frequency122GHz,
+	Band134G:  Frequency134GHz,
+	Band241G:  Frequency241GHz,
+	BandLight: FrequencyLight,
+}
+
+// csvFrequency combines the frequency and the band column. If both are given, they must match. If only
+// the band is given, it is converted into a band designator or, below 50 MHz, into the lower band edge.
+func csvFrequency(frequency QSOFrequency, band CategoryBand) (QSOFrequency, error) {
+	if band == "" {
+		if frequency == "" {
+			return "", fmt.Errorf("missing frequency")
+		}
+		return frequency, nil
+	}
+
+	designator, ok := csvBandDesignators[band]
+	if !ok {
+		designator, ok = csvBandEdges[band]
+	}
+	if !ok {
+		// the band may also be given as band designator, e.g. 144
+		designator = QSOFrequency(band)
+		_, ok = csvBandDesignators[designator.ToBand()]
+		ok = ok && !designator.IsFrequency()
+	}
+	if !ok {
+		return "", fmt.Errorf("%s is not a valid band", band)
+	}
+	if frequency == "" {
+		return designator, nil
+	}
+	if frequency.ToBand() != designator.ToBand() {
+		return "", fmt.Errorf("frequency %s is not within the band %s", frequency, band)
+	}
+	return frequency, nil
+}
+
+func setExchangeElement(exchange []string, index int, value string) []string {
+	for len(exchange) < index {
+		exchange = append(exchange, "")
+	}
+	exchange[index-1] = value
+	// trailing empty elements are dropped, they are not part of the Cabrillo QSO line
+	for len(exchange) > 0 && exchange[len(exchange)-1] == "" {
+		exchange = exchange[:len(exchange)-1]
+	}
+	return exchange
+}
@@ -0,0 +1,107 @@
+package cabrillo
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVRoundtrip(t *testing.T) {
+	file, err := os.Open("testdata/cqww.v3.cabrillo")
+	require.NoError(t, err)
+	defer file.Close()
+	log, err := Read(file)
+	require.NoError(t, err)
+
+	buffer := &bytes.Buffer{}
+	err = WriteCSV(buffer, log.QSOData)
+	require.NoError(t, err)
+
+	qsos, rowErrors, err := ReadCSV(buffer, nil)
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	assert.Equal(t, log.QSOData, qsos)
+}
+
+func TestReadCSV_Mapping(t *testing.T) {
+	data := `Freq,Mode,Date,Time,MyCall,RST S,Zone S,Call,RST R,Zone R,Comment
+14025,cw,2024-11-23,711,DL1ABC,599,14,W1AW,599,5,first
+14026,CW,2024-11-23,07:12,DL1ABC,599,14,N5KO,599,,second
+7025,CW,2024-11-23,0713,DL1ABC,599,14,N5KO,599,4,third
+7025,CW,yesterday,0714,DL1ABC,599,14,K1AR,599,5,fourth
+`
+	mapping, err := ParseCSVMapping("freq=frequency, mode=mode, date=date, time=time, mycall=sent_call, rst s=sent_exch_1, zone s=sent_exch_2, call=rcvd_call, rst r=rcvd_exch_1, zone r=rcvd_exch_2")
+	require.NoError(t, err)
+
+	qsos, rowErrors, err := ReadCSV(bytes.NewBufferString(data), mapping)
+	require.NoError(t, err)
+
+	require.Len(t, qsos, 2)
+	assert.Equal(t, QSOFrequency("14025"), qsos[0].Frequency)
+	assert.Equal(t, QSOModeCW, qsos[0].Mode)
+	assert.Equal(t, "2024-11-23 0711", formatTimestamp(qsos[0].Timestamp))
+	assert.Equal(t, []string{"599", "14"}, qsos[0].Sent.Exchange)
+	assert.Equal(t, []string{"599", "5"}, qsos[0].Received.Exchange)
+	assert.Equal(t, "N5KO", qsos[1].Received.Call.String())
+
+	require.Len(t, rowErrors, 2)
+	assert.Equal(t, 3, rowErrors[0].Row)
+	assert.Equal(t, 5, rowErrors[1].Row)
+}
+
+func TestReadCSV_MissingColumn(t *testing.T) {
+	_, _, err := ReadCSV(bytes.NewBufferString("frequency,mode,date,time\n"), nil)
+	assert.Error(t, err)
+}
+
+func TestReadCSV_Band(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency string
+		band      string
+		expected  QSOFrequency
+		invalid   bool
+	}{
+		{name: "frequency only", frequency: "14025", expected: "14025"},
+		{name: "frequency and matching band", frequency: "14025", band: "20m", expected: "14025"},
+		{name: "frequency and other band", frequency: "14025", band: "40M", invalid: true},
+		{name: "HF band only", band: "40M", expected: "7000"},
+		{name: "VHF band only", band: "2M", expected: Frequency144MHz},
+		{name: "band designator", band: "432", expected: Frequency432MHz},
+		{name: "unknown band", band: "11M", invalid: true},
+		{name: "neither frequency nor band", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "frequency,band,mode,date,time,sent_call,sent_exch_1,rcvd_call,rcvd_exch_1\n" +
+				tt.frequency + "," + tt.band + ",CW,2024-11-23,0711,DL1ABC,599,W1AW,599\n"
+
+			qsos, rowErrors, err := ReadCSV(bytes.NewBufferString(data), nil)
+			require.NoError(t, err)
+
+			if tt.invalid {
+				assert.Empty(t, qsos)
+				assert.Len(t, rowErrors, 1)
+				return
+			}
+			assert.Empty(t, rowErrors)
+			require.Len(t, qsos, 1)
+			assert.Equal(t, tt.expected, qsos[0].Frequency)
+		})
+	}
+}
+
+func TestReadCSV_EmptyExchangeElement(t *testing.T) {
+	data := "band,mode,date,time,sent_call,sent_exch_1,sent_exch_2,sent_exch_3,rcvd_call,rcvd_exch_1,rcvd_exch_2,rcvd_exch_3\n" +
+		"20M,CW,2024-11-23,0711,DL1ABC,599,,14,W1AW,599,1,5\n"
+
+	qsos, rowErrors, err := ReadCSV(bytes.NewBufferString(data), nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, qsos)
+	require.Len(t, rowErrors, 1)
+	assert.EqualError(t, rowErrors[0], "row 2: missing sent exchange element 2")
+}
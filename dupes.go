@@ -0,0 +1,36 @@
+package cabrillo
+
+// Dupe describes a QSO that duplicates a former QSO. Index and Original refer to the position of the QSOs in the given slice.
+type Dupe struct {
+	Index    int
+	Original int
+}
+
+type dupeKey struct {
+	call string
+	band CategoryBand
+	mode QSOMode
+}
+
+// FindDupes returns all QSOs that were made with the same station on the same band as a former QSO.
+// If perMode is true, QSOs with the same station on the same band but in different modes are no dupes.
+func FindDupes(qsos []QSO, perMode bool) []Dupe {
+	var result []Dupe
+	originals := make(map[dupeKey]int, len(qsos))
+	for i, qso := range qsos {
+		key := dupeKey{
+			call: qso.Received.Call.String(),
+			band: qso.Frequency.ToBand(),
+		}
+		if perMode {
+			key.mode = qso.Mode
+		}
+		original, found := originals[key]
+		if found {
+			result = append(result, Dupe{Index: i, Original: original})
+			continue
+		}
+		originals[key] = i
+	}
+	return result
+}
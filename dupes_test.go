@@ -0,0 +1,62 @@
+package cabrillo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDupes(t *testing.T) {
+	tests := []struct {
+		name     string
+		qsos     []QSO
+		perMode  bool
+		expected []Dupe
+	}{
+		{
+			name: "no QSOs",
+		},
+		{
+			name: "different bands",
+			qsos: []QSO{
+				scoringTestQSO("14025", QSOModeCW, "W1AW", "5"),
+				scoringTestQSO("7025", QSOModeCW, "W1AW", "5"),
+			},
+		},
+		{
+			name: "same band, different modes",
+			qsos: []QSO{
+				scoringTestQSO("14025", QSOModeCW, "W1AW", "5"),
+				scoringTestQSO("7025", QSOModeCW, "W1AW", "5"),
+				scoringTestQSO("14250", QSOModePhone, "W1AW", "5"),
+				scoringTestQSO("14030", QSOModeCW, "W1AW", "5"),
+			},
+			expected: []Dupe{{Index: 2, Original: 0}, {Index: 3, Original: 0}},
+		},
+		{
+			name: "same band, different modes, per mode",
+			qsos: []QSO{
+				scoringTestQSO("14025", QSOModeCW, "W1AW", "5"),
+				scoringTestQSO("7025", QSOModeCW, "W1AW", "5"),
+				scoringTestQSO("14250", QSOModePhone, "W1AW", "5"),
+				scoringTestQSO("14030", QSOModeCW, "W1AW", "5"),
+			},
+			perMode:  true,
+			expected: []Dupe{{Index: 3, Original: 0}},
+		},
+		{
+			name: "every dupe refers to the first QSO",
+			qsos: []QSO{
+				scoringTestQSO(Frequency144MHz, QSOModeFM, "DL2XYZ", "5"),
+				scoringTestQSO(Frequency144MHz, QSOModeFM, "DL2XYZ", "5"),
+				scoringTestQSO(Frequency144MHz, QSOModeFM, "DL2XYZ", "5"),
+			},
+			expected: []Dupe{{Index: 1, Original: 0}, {Index: 2, Original: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FindDupes(tt.qsos, tt.perMode))
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package cabrillo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ftl/hamradio/locator"
+)
+
+// ExchangeField describes the meaning of an element of the exchange.
+type ExchangeField string
+
+const (
+	RSTField     ExchangeField = "RST"
+	SerialField  ExchangeField = "SERIAL"
+	CQZoneField  ExchangeField = "CQ-ZONE"
+	ITUZoneField ExchangeField = "ITU-ZONE"
+	StateField   ExchangeField = "STATE"
+	SectionField ExchangeField = "SECTION"
+	NameField    ExchangeField = "NAME"
+	PowerField   ExchangeField = "POWER"
+	LocatorField ExchangeField = "LOCATOR"
+	OtherField   ExchangeField = "OTHER"
+)
+
+// IsConstant indicates if the value of this field is expected to be the same in every QSO with the same station.
+func (f ExchangeField) IsConstant() bool {
+	switch f {
+	case RSTField, SerialField, OtherField:
+		return false
+	default:
+		return true
+	}
+}
+
+// ExchangeDefinition describes the elements of the exchange in the order of the QSO columns.
+type ExchangeDefinition []ExchangeField
+
+var (
+	CQWWExchange   = ExchangeDefinition{RSTField, CQZoneField}
+	CQWPXExchange  = ExchangeDefinition{RSTField, SerialField}
+	NAQPExchange   = ExchangeDefinition{NameField, StateField}
+	CQVHFExchange  = ExchangeDefinition{LocatorField}
+	SerialExchange = ExchangeDefinition{RSTField, SerialField}
+)
+
+var knownExchanges = map[ContestIdentifier]ExchangeDefinition{
+	"CQ-WW-CW":    CQWWExchange,
+	"CQ-WW-SSB":   CQWWExchange,
+	"CQ-WW-RTTY":  {RSTField, CQZoneField, StateField},
+	"CQ-WPX-CW":   CQWPXExchange,
+	"CQ-WPX-SSB":  CQWPXExchange,
+	"CQ-WPX-RTTY": CQWPXExchange,
+	"CQ-VHF":      CQVHFExchange,
+	"NAQP-CW":     NAQPExchange,
+	"NAQP-SSB":    NAQPExchange,
+	"NAQP-RTTY":   NAQPExchange,
+	"RDXC":        {RSTField, OtherField},
+}
+
+// ExchangeDefinitionFor returns the exchange definition of a known contest.
+func ExchangeDefinitionFor(contest ContestIdentifier) (ExchangeDefinition, bool) {
+	result, ok := knownExchanges[ContestIdentifier(strings.ToUpper(strings.TrimSpace(string(contest))))]
+	return result, ok
+}
+
+// Index returns the index of the given field within the exchange, or -1 if the exchange does not contain the field.
+func (d ExchangeDefinition) Index(field ExchangeField) int {
+	for i, f := range d {
+		if f == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// ErrNoSuchField indicates that the exchange definition or the QSO info does not contain the requested field.
+var ErrNoSuchField = errors.New("no such field in the exchange")
+
+// Element returns the value of the given field in the exchange.
+func (i QSOInfo) Element(definition ExchangeDefinition, field ExchangeField) (string, error) {
+	index := definition.Index(field)
+	if index == -1 || index >= len(i.Exchange) {
+		return "", fmt.Errorf("%s: %w", field, ErrNoSuchField)
+	}
+	return i.Exchange[index], nil
+}
+
+// RST is a signal report. Tone is 0 for phone reports.
+type RST struct {
+	Readability int
+	Strength    int
+	Tone        int
+}
+
+func (r RST) String() string {
+	if r.Tone == 0 {
+		return fmt.Sprintf("%d%d", r.Readability, r.Strength)
+	}
+	return fmt.Sprintf("%d%d%d", r.Readability, r.Strength, r.Tone)
+}
+
+// ParseRST parses a signal report that is appropriate for the given mode: RS for phone and FM, RST for all other modes.
+func ParseRST(s string, mode QSOMode) (RST, error) {
+	expectedLength := 3
+	if mode == QSOModePhone || mode == QSOModeFM {
+		expectedLength = 2
+	}
+	if len(s) != expectedLength {
+		return RST{}, fmt.Errorf("%q is not a valid report for mode %s", s, mode)
+	}
+	digits := make([]int, len(s))
+	for i, c := range s {
+		if c < '1' || c > '9' {
+			return RST{}, fmt.Errorf("%q is not a valid report", s)
+		}
+		digits[i] = int(c - '0')
+	}
+	if digits[0] > 5 {
+		return RST{}, fmt.Errorf("%q is not a valid report, the readability must be 1-5", s)
+	}
+
+	result := RST{Readability: digits[0], Strength: digits[1]}
+	if len(digits) == 3 {
+		result.Tone = digits[2]
+	}
+	return result, nil
+}
+
+// RST returns the signal report, validated for the given mode.
+func (i QSOInfo) RST(definition ExchangeDefinition, mode QSOMode) (RST, error) {
+	value, err := i.Element(definition, RSTField)
+	if err != nil {
+		return RST{}, err
+	}
+	return ParseRST(value, mode)
+}
+
+// Serial returns the serial number.
+func (i QSOInfo) Serial(definition ExchangeDefinition) (int, error) {
+	value, err := i.Element(definition, SerialField)
+	if err != nil {
+		return 0, err
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("%q is not a valid serial number", value)
+	}
+	return result, nil
+}
+
+// CQZone returns the CQ zone (1-40).
+func (i QSOInfo) CQZone(definition ExchangeDefinition) (int, error) {
+	return i.zone(definition, CQZoneField, 40)
+}
+
+// ITUZone returns the ITU zone (1-90).
+func (i QSOInfo) ITUZone(definition ExchangeDefinition) (int, error) {
+	return i.zone(definition, ITUZoneField, 90)
+}
+
+func (i QSOInfo) zone(definition ExchangeDefinition, field ExchangeField, maxZone int) (int, error) {
+	value, err := i.Element(definition, field)
+	if err != nil {
+		return 0, err
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil || result < 1 || result > maxZone {
+		return 0, fmt.Errorf("%q is not a valid %s", value, strings.ToLower(string(field)))
+	}
+	return result, nil
+}
+
+// State returns the state or province.
+func (i QSOInfo) State(definition ExchangeDefinition) (string, error) {
+	return i.word(definition, StateField)
+}
+
+// Section returns the ARRL/RAC section.
+func (i QSOInfo) Section(definition ExchangeDefinition) (string, error) {
+	return i.word(definition, SectionField)
+}
+
+// Name returns the operator's name.
+func (i QSOInfo) Name(definition ExchangeDefinition) (string, error) {
+	return i.word(definition, NameField)
+}
+
+func (i QSOInfo) word(definition ExchangeDefinition, field ExchangeField) (string, error) {
+	value, err := i.Element(definition, field)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", fmt.Errorf("the %s is empty", strings.ToLower(string(field)))
+	}
+	return strings.ToUpper(value), nil
+}
+
+// Power returns the transmitter power in watts. The abbreviations K and KW are interpreted as 1000 watts,
+// a trailing W is ignored.
+func (i QSOInfo) Power(definition ExchangeDefinition) (int, error) {
+	value, err := i.Element(definition, PowerField)
+	if err != nil {
+		return 0, err
+	}
+	normalized := strings.ToUpper(value)
+	switch normalized {
+	case "K", "KW":
+		return 1000, nil
+	}
+	result, err := strconv.Atoi(strings.TrimSuffix(normalized, "W"))
+	if err != nil || result <= 0 {
+		return 0, fmt.Errorf("%q is not a valid power", value)
+	}
+	return result, nil
+}
+
+// Locator returns the Maidenhead grid locator.
+func (i QSOInfo) Locator(definition ExchangeDefinition) (locator.Locator, error) {
+	value, err := i.Element(definition, LocatorField)
+	if err != nil {
+		return locator.Locator{}, err
+	}
+	return locator.Parse(value)
+}
@@ -0,0 +1,96 @@
+package cabrillo
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio/locator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRST(t *testing.T) {
+	tt := []struct {
+		value    string
+		mode     QSOMode
+		expected RST
+		invalid  bool
+	}{
+		{value: "599", mode: QSOModeCW, expected: RST{5, 9, 9}},
+		{value: "579", mode: QSOModeRTTY, expected: RST{5, 7, 9}},
+		{value: "59", mode: QSOModePhone, expected: RST{5, 9, 0}},
+		{value: "59", mode: QSOModeFM, expected: RST{5, 9, 0}},
+		{value: "59", mode: QSOModeCW, invalid: true},
+		{value: "599", mode: QSOModePhone, invalid: true},
+		{value: "699", mode: QSOModeCW, invalid: true},
+		{value: "5N9", mode: QSOModeCW, invalid: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.value+" "+string(tc.mode), func(t *testing.T) {
+			actual, err := ParseRST(tc.value, tc.mode)
+			if tc.invalid {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+				assert.Equal(t, tc.value, actual.String())
+			}
+		})
+	}
+}
+
+func TestQSOInfo_TypedAccessors(t *testing.T) {
+	definition := ExchangeDefinition{RSTField, SerialField, CQZoneField, ITUZoneField, StateField, NameField, PowerField, LocatorField}
+	info := QSOInfo{Exchange: []string{"599", "0042", "14", "28", "by", "hans", "KW", "jn59"}}
+
+	rst, err := info.RST(definition, QSOModeCW)
+	require.NoError(t, err)
+	assert.Equal(t, RST{5, 9, 9}, rst)
+	_, err = info.RST(definition, QSOModePhone)
+	assert.Error(t, err)
+
+	serial, err := info.Serial(definition)
+	require.NoError(t, err)
+	assert.Equal(t, 42, serial)
+
+	cqZone, err := info.CQZone(definition)
+	require.NoError(t, err)
+	assert.Equal(t, 14, cqZone)
+
+	ituZone, err := info.ITUZone(definition)
+	require.NoError(t, err)
+	assert.Equal(t, 28, ituZone)
+
+	state, err := info.State(definition)
+	require.NoError(t, err)
+	assert.Equal(t, "BY", state)
+
+	name, err := info.Name(definition)
+	require.NoError(t, err)
+	assert.Equal(t, "HANS", name)
+
+	power, err := info.Power(definition)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, power)
+
+	loc, err := info.Locator(definition)
+	require.NoError(t, err)
+	assert.Equal(t, locator.MustParse("JN59"), loc)
+
+	_, err = info.Section(definition)
+	assert.ErrorIs(t, err, ErrNoSuchField)
+}
+
+func TestQSOInfo_InvalidZone(t *testing.T) {
+	info := QSOInfo{Exchange: []string{"599", "41"}}
+	_, err := info.CQZone(CQWWExchange)
+	assert.Error(t, err)
+}
+
+func TestExchangeDefinitionFor(t *testing.T) {
+	definition, ok := ExchangeDefinitionFor("cq-ww-cw ")
+	assert.True(t, ok)
+	assert.Equal(t, CQWWExchange, definition)
+
+	_, ok = ExchangeDefinitionFor("UNKNOWN")
+	assert.False(t, ok)
+}
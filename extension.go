@@ -0,0 +1,20 @@
+package cabrillo
+
+// Extensions stores the values of user-defined tags, which are registered with Reader.RegisterTag
+// and Writer.RegisterTag.
+type Extensions map[Tag]any
+
+// Extension returns the value that is stored in the log for the given tag. The result is false
+// if there is no value for this tag or the value is not of type T.
+func Extension[T any](l *Log, tag Tag) (T, bool) {
+	value, ok := l.Extensions[tag].(T)
+	return value, ok
+}
+
+// SetExtension stores the value for the given tag in the log.
+func SetExtension[T any](l *Log, tag Tag, value T) {
+	if l.Extensions == nil {
+		l.Extensions = make(Extensions)
+	}
+	l.Extensions[tag] = value
+}
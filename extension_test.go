@@ -0,0 +1,91 @@
+package cabrillo
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const xPowerTag Tag = "X-POWER-WATTS"
+
+type powerWatts int
+
+func TestExtensions_Roundtrip(t *testing.T) {
+	input := "START-OF-LOG: 3.0\nX-POWER-WATTS: 100\nEND-OF-LOG:\n"
+
+	reader := NewReader()
+	reader.RegisterTag(xPowerTag, func(l *Log, value string) error {
+		watts, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		SetExtension(l, xPowerTag, powerWatts(watts))
+		return nil
+	})
+	log, err := reader.Read(strings.NewReader(input))
+	require.NoError(t, err)
+
+	watts, ok := Extension[powerWatts](log, xPowerTag)
+	assert.True(t, ok)
+	assert.Equal(t, powerWatts(100), watts)
+	_, ok = Extension[string](log, xPowerTag)
+	assert.False(t, ok, "wrong type")
+	assert.Empty(t, log.Custom)
+
+	SetExtension(log, xPowerTag, powerWatts(5))
+	writer := NewWriter()
+	writer.RegisterTag(xPowerTag, func(l *Log) []string {
+		watts, ok := Extension[powerWatts](l, xPowerTag)
+		if !ok {
+			return nil
+		}
+		return []string{strconv.Itoa(int(watts))}
+	})
+	buffer := &bytes.Buffer{}
+	err = writer.WriteWithTags(buffer, log, false, true, xPowerTag)
+	require.NoError(t, err)
+	assert.Equal(t, "START-OF-LOG: 3.0\nX-POWER-WATTS: 5\nEND-OF-LOG:\n", buffer.String())
+}
+
+func TestExtensions_ScopedPerReader(t *testing.T) {
+	input := "START-OF-LOG: 3.0\nX-POWER-WATTS: 100\nEND-OF-LOG:\n"
+
+	reader := NewReader()
+	reader.RegisterTag(xPowerTag, func(l *Log, value string) error {
+		return nil
+	})
+
+	log, err := Read(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"100"}, log.Custom.Values(xPowerTag))
+	assert.Nil(t, log.Extensions)
+}
+
+func TestExtensions_StructLiterals(t *testing.T) {
+	input := "START-OF-LOG: 3.0\nX-POWER-WATTS: 100\nEND-OF-LOG:\n"
+
+	reader := &Reader{MaxQSOs: 10}
+	reader.RegisterTag(xPowerTag, func(l *Log, value string) error {
+		SetExtension(l, xPowerTag, value)
+		return nil
+	})
+	log, err := reader.Read(strings.NewReader(input))
+	require.NoError(t, err)
+	watts, ok := Extension[string](log, xPowerTag)
+	assert.True(t, ok)
+	assert.Equal(t, "100", watts)
+
+	writer := &Writer{}
+	writer.RegisterTag(xPowerTag, func(l *Log) []string {
+		watts, _ := Extension[string](l, xPowerTag)
+		return []string{watts}
+	})
+	buffer := &bytes.Buffer{}
+	err = writer.WriteWithTags(buffer, log, false, true, xPowerTag)
+	require.NoError(t, err)
+	assert.Equal(t, input, buffer.String())
+}
@@ -0,0 +1,184 @@
+package cabrillo
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+type LineEnding string
+
+const (
+	LF   LineEnding = "\n"
+	CRLF LineEnding = "\r\n"
+)
+
+type TransmitterColumn int
+
+const (
+	// TransmitterAuto writes the transmitter column only if the log indicates more than one transmitter.
+	TransmitterAuto TransmitterColumn = iota
+	TransmitterAlways
+	TransmitterNever
+)
+
+// Formatter writes logs in a canonical form: uppercase tags, enum values and callsigns, trimmed values,
+// a deterministic order of the header tags and aligned QSO columns. Custom tags keep their
+// original order. Formatting an already formatted log results in the identical output.
+type Formatter struct {
+	// LineEnding terminates each line. The default is LF.
+	LineEnding LineEnding
+	// Transmitter controls if the transmitter column is written.
+	Transmitter TransmitterColumn
+	// SortQSOs emits the QSOs in the order of CompareQSOs.
+	SortQSOs bool
+	// Transliterate replaces all non-ASCII characters with ASCII equivalents, see Transliterate.
+	Transliterate bool
+}
+
+// Format reads a log from r and writes it in the canonical form to w.
+func Format(w io.Writer, r io.Reader) error {
+	return Formatter{}.Format(w, r)
+}
+
+// Format reads a log from r and writes it in the canonical form to w.
+func (f Formatter) Format(w io.Writer, r io.Reader) error {
+	l, err := Read(r)
+	if err != nil {
+		return err
+	}
+	return f.FormatLog(w, l)
+}
+
+// FormatLog writes the given log in the canonical form to w. The given log is not modified.
+func (f Formatter) FormatLog(w io.Writer, l *Log) error {
+	canonical := canonicalLog(l)
+
+	tags := make([]Tag, 0, len(defaultTagOrder)+len(canonical.Custom))
+	tags = append(tags, defaultTagOrder...)
+	tags = append(tags, canonical.Custom.Tags()...)
+
+	writer := NewWriter(
+		WithTransmitterColumn(f.Transmitter),
+		WithTagOrder(tags...),
+		WithLineEnding(f.LineEnding),
+		WithAlignedQSOs(true),
+		WithSortedQSOs(f.SortQSOs),
+		WithTransliteration(f.Transliterate),
+	)
+	writer.upperCase = true
+	return writer.WriteLog(w, canonical)
+}
+
+// NeedsTransmitterColumn indicates if the QSO data of the given log needs the transmitter column,
+// because the category or the QSO data indicates more than one transmitter.
+func NeedsTransmitterColumn(l *Log) bool {
+	switch l.Category.Transmitter {
+	case "", OneTransmitter, SWL:
+	default:
+		return true
+	}
+	for _, qsos := range [][]QSO{l.QSOData, l.IgnoredQSOs} {
+		for _, qso := range qsos {
+			if qso.Transmitter != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// canonicalLog returns a copy of the given log with normalized values.
+func canonicalLog(l *Log) *Log {
+	result := *l
+
+	result.CabrilloVersion = strings.TrimSpace(l.CabrilloVersion)
+	result.Contest = ContestIdentifier(canonicalValue(string(l.Contest)))
+	result.Category = Category{
+		Assisted:    CategoryAssisted(canonicalValue(string(l.Category.Assisted))),
+		Band:        CategoryBand(canonicalValue(string(l.Category.Band))),
+		Mode:        CategoryMode(canonicalValue(string(l.Category.Mode))),
+		Operator:    CategoryOperator(canonicalValue(string(l.Category.Operator))),
+		Power:       CategoryPower(canonicalValue(string(l.Category.Power))),
+		Station:     CategoryStation(canonicalValue(string(l.Category.Station))),
+		Time:        CategoryTime(canonicalValue(string(l.Category.Time))),
+		Transmitter: CategoryTransmitter(canonicalValue(string(l.Category.Transmitter))),
+		Overlay:     CategoryOverlay(canonicalValue(string(l.Category.Overlay))),
+	}
+	result.Club = strings.TrimSpace(l.Club)
+	result.CreatedBy = strings.TrimSpace(l.CreatedBy)
+	result.Email = strings.TrimSpace(l.Email)
+	result.Location = strings.TrimSpace(l.Location)
+	result.Name = strings.TrimSpace(l.Name)
+	result.Address = Address{
+		Text:          trimLines(l.Address.Text),
+		City:          strings.TrimSpace(l.Address.City),
+		StateProvince: strings.TrimSpace(l.Address.StateProvince),
+		Postalcode:    strings.TrimSpace(l.Address.Postalcode),
+		Country:       strings.TrimSpace(l.Address.Country),
+	}
+	result.Soapbox = trimLines(l.Soapbox)
+
+	result.Custom = make(CustomTags, 0, len(l.Custom))
+	for _, value := range l.Custom {
+		result.Custom.Add(Tag(canonicalValue(string(value.Tag))), trimLines(value.Value))
+	}
+
+	result.QSOData = canonicalQSOs(l.QSOData)
+	result.IgnoredQSOs = canonicalQSOs(l.IgnoredQSOs)
+
+	return &result
+}
+
+func canonicalValue(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func canonicalQSOs(qsos []QSO) []QSO {
+	result := make([]QSO, len(qsos))
+	for i, qso := range qsos {
+		qso.Frequency = QSOFrequency(canonicalValue(string(qso.Frequency)))
+		qso.Mode = QSOMode(canonicalValue(string(qso.Mode)))
+		qso.Sent.Exchange = canonicalExchange(qso.Sent.Exchange)
+		qso.Received.Exchange = canonicalExchange(qso.Received.Exchange)
+		result[i] = qso
+	}
+	return result
+}
+
+func canonicalExchange(exchange []string) []string {
+	result := make([]string, len(exchange))
+	for i, element := range exchange {
+		result[i] = strings.TrimSpace(element)
+	}
+	return result
+}
+
+// lineEndingWriter replaces the LF line endings written by the log writer with the configured line ending.
+type lineEndingWriter struct {
+	w          io.Writer
+	lineEnding []byte
+}
+
+func newLineEndingWriter(w io.Writer, lineEnding LineEnding) io.Writer {
+	if lineEnding == "" || lineEnding == LF {
+		return w
+	}
+	return &lineEndingWriter{w: w, lineEnding: []byte(lineEnding)}
+}
+
+func (w *lineEndingWriter) Write(p []byte) (int, error) {
+	_, err := w.w.Write(bytes.ReplaceAll(p, []byte(LF), w.lineEnding))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,114 @@
+package cabrillo
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_Idempotent(t *testing.T) {
+	entries, err := os.ReadDir("testdata")
+	require.NoError(t, err)
+
+	for _, lineEnding := range []LineEnding{LF, CRLF} {
+		formatter := Formatter{LineEnding: lineEnding}
+		for _, entry := range entries {
+			t.Run(entry.Name(), func(t *testing.T) {
+				original, err := os.Open("testdata/" + entry.Name())
+				require.NoError(t, err)
+				defer original.Close()
+
+				once := &bytes.Buffer{}
+				err = formatter.Format(once, original)
+				require.NoError(t, err)
+
+				twice := &bytes.Buffer{}
+				err = formatter.Format(twice, bytes.NewReader(once.Bytes()))
+				require.NoError(t, err)
+
+				assert.Equal(t, once.String(), twice.String())
+				assert.Equal(t, len(strings.Split(once.String(), "\n")), len(strings.Split(once.String(), string(lineEnding))))
+			})
+		}
+	}
+}
+
+func TestFormat_Canonical(t *testing.T) {
+	input := `start-of-log: 3.0
+x-b: second custom tag
+callsign: dl1abc/p
+  contest:   cq-ww-cw  
+category-mode: cw
+x-a: first custom tag
+qso: 7025 cw 2024-11-23 0711 dl1abc/p 599 14 w1aw 599 5
+qso: 14025 CW 2024-11-23 0712 dl1abc/p 599 14 ja1abcd 599 25
+end-of-log:
+`
+	expected := `START-OF-LOG: 3.0
+CONTEST: CQ-WW-CW
+CALLSIGN: DL1ABC/P
+CLAIMED-SCORE: 0
+CATEGORY-MODE: CW
+CERTIFICATE: NO
+X-B: second custom tag
+X-A: first custom tag
+QSO:  7025 CW 2024-11-23 0711 DL1ABC/P 599 14 W1AW    599 5
+QSO: 14025 CW 2024-11-23 0712 DL1ABC/P 599 14 JA1ABCD 599 25
+END-OF-LOG:
+`
+	output := &bytes.Buffer{}
+	err := Format(output, strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, output.String())
+}
+
+func TestFormat_AlignExchangesSeparately(t *testing.T) {
+	qso := func(frequency QSOFrequency, sent []string, call string, received []string) QSO {
+		return QSO{
+			Frequency: frequency,
+			Mode:      QSOModeCW,
+			Timestamp: time.Date(2024, time.November, 23, 7, 11, 0, 0, time.UTC),
+			Sent:      QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: sent},
+			Received:  QSOInfo{Call: callsign.MustParse(call), Exchange: received},
+		}
+	}
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.QSOData = []QSO{
+		qso("7025", []string{"599", "14"}, "W1AW", []string{"599", "5"}),
+		qso("14025", []string{"599", "14", "A"}, "JA1ABCD", []string{"599", "25", "A"}),
+		qso("14026", []string{"599", "1400"}, "K1AR", []string{"599"}),
+	}
+	expected := `QSO:  7025 CW 2024-11-23 0711 DL1ABC 599 14     W1AW    599 5
+QSO: 14025 CW 2024-11-23 0711 DL1ABC 599 14   A JA1ABCD 599 25 A
+QSO: 14026 CW 2024-11-23 0711 DL1ABC 599 1400   K1AR    599
+`
+	output := &bytes.Buffer{}
+	err := Formatter{}.FormatLog(output, log)
+	require.NoError(t, err)
+
+	assert.Contains(t, output.String(), expected)
+}
+
+func TestFormat_UppercaseCallsignsOnlyInFormatter(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Callsign = callsign.Callsign{BaseCall: "DL1ABC", WorkingCondition: "P"}
+
+	written := &bytes.Buffer{}
+	err := WriteWithTags(written, log, false, true, CallsignTag)
+	require.NoError(t, err)
+	assert.Equal(t, "START-OF-LOG: 3.0\nCALLSIGN: DL1ABC/p\nEND-OF-LOG:\n", written.String())
+
+	formatted := &bytes.Buffer{}
+	err = Formatter{}.FormatLog(formatted, log)
+	require.NoError(t, err)
+	assert.Contains(t, formatted.String(), "CALLSIGN: DL1ABC/P\n")
+}
@@ -0,0 +1,313 @@
+package cabrillo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LogWriter keeps a Cabrillo file on disk up to date while the log is created, e.g. during the contest.
+// It writes the header once, appends each QSO as it is logged and writes END-OF-LOG on Close. Each write is
+// synced to disk. The file is rewritten completely only if the header is updated or if the first QSO of another
+// transmitter requires the transmitter column. The options WithAlignedQSOs and WithSortedQSOs have no effect
+// on a LogWriter. A LogWriter is not safe for concurrent use.
+type LogWriter struct {
+	filename string
+	writer   *Writer
+	log      *Log
+	config   writeConfig
+	repairs  []Repair
+	file     *os.File
+	out      io.Writer
+}
+
+// CreateLogWriter creates a new file with the given name and writes the given header to it. If the header contains
+// QSO data, it is written as well. It fails if the file already exists, use OpenLogWriter to continue an existing log.
+func CreateLogWriter(filename string, header *Log, options ...WriterOption) (*LogWriter, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	result := newLogWriter(filename, copyLog(header), options)
+	result.setFile(file)
+
+	err = result.writeAll(result.out)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+// OpenLogWriter opens an existing file that was written by a LogWriter to continue the log, e.g. after the logger
+// crashed or was restarted. The log is recovered like ReadRecover does, the repairs are available through Repairs.
+// If the trailing END-OF-LOG line or an incomplete last line is the only damage, it is removed and everything else
+// is kept as it is. Otherwise, the file is rewritten with the recovered log. The line ending of the existing file
+// is kept, regardless of the WithLineEnding option. The transmitter column of the existing QSO lines is kept as
+// well, unless it conflicts with the WithTransmitterColumn option, then the file is rewritten. Tags that are
+// registered on a Writer or Reader are kept in the file, but they are not available through the log of the LogWriter.
+func OpenLogWriter(filename string, options ...WriterOption) (*LogWriter, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	l, repairs, err := ReadRecover(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if lineEnding, found := detectLineEnding(data); found {
+		options = append(options[:len(options):len(options)], WithLineEnding(lineEnding))
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+	result := newLogWriter(filename, l, options)
+	result.repairs = repairs
+	result.setFile(file)
+
+	size, canContinue := continuationOffset(data, repairs)
+	hasTransmitterColumn, found := detectTransmitterColumn(data)
+	switch {
+	case !found, result.writer.transmitter == TransmitterAuto:
+	case hasTransmitterColumn != (result.writer.transmitter == TransmitterAlways):
+		// the file is rewritten to apply the transmitter column option to all QSOs
+		canContinue = false
+	}
+	if canContinue {
+		err = file.Truncate(size)
+		if err == nil {
+			// resolve the configuration for the QSOs that are appended
+			err = result.writeAll(io.Discard)
+		}
+		if found {
+			// the appended QSOs must have the same columns as the existing ones
+			result.config.appendTX = hasTransmitterColumn
+		}
+	} else {
+		err = result.rewrite()
+	}
+	if err != nil {
+		result.file.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+// detectLineEnding returns the line ending of the first line of the given data.
+func detectLineEnding(data []byte) (LineEnding, bool) {
+	end := bytes.IndexByte(data, '\n')
+	switch {
+	case end == -1:
+		return "", false
+	case end > 0 && data[end-1] == '\r':
+		return CRLF, true
+	default:
+		return LF, true
+	}
+}
+
+// detectTransmitterColumn returns if the first valid QSO or X-QSO line of the given data has a transmitter column.
+func detectTransmitterColumn(data []byte) (bool, bool) {
+	for _, line := range strings.Split(string(data), "\n") {
+		tagStr, value, _ := strings.Cut(line, ":")
+		tag := Tag(strings.ToUpper(strings.TrimSpace(tagStr)))
+		if tag != QSOTag && tag != XQSOTag {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if _, err := ParseQSO(value); err != nil {
+			continue
+		}
+		return len(qsoColumnSeparator.Split(value, -1))%2 == 1, true
+	}
+	return false, false
+}
+
+// continuationOffset returns the size of the data that can be kept to continue the log with the given repairs.
+// The data can only be continued if it ends with the log, i.e. with END-OF-LOG or with the last line of the log.
+func continuationOffset(data []byte, repairs []Repair) (int64, bool) {
+	if len(repairs) == 0 {
+		// the log ends with END-OF-LOG, which must be the last line
+		content := bytes.TrimRight(data, " \t\r\n")
+		start := bytes.LastIndexByte(content, '\n') + 1
+		tagStr, _, _ := strings.Cut(string(content[start:]), ":")
+		if Tag(strings.ToUpper(strings.TrimSpace(tagStr))) != EndOfLogTag {
+			return 0, false
+		}
+		return int64(start), true
+	}
+	for _, repair := range repairs {
+		switch repair.Kind {
+		case AddedEndOfLog, SkippedIncompleteLine:
+		default:
+			return 0, false
+		}
+	}
+	return int64(bytes.LastIndexByte(data, '\n') + 1), true
+}
+
+func newLogWriter(filename string, l *Log, options []WriterOption) *LogWriter {
+	return &LogWriter{
+		filename: filename,
+		writer:   NewWriter(options...),
+		log:      l,
+	}
+}
+
+func (w *LogWriter) setFile(file *os.File) {
+	w.file = file
+	w.out = w.writer.output(context.Background(), file)
+}
+
+// writeAll writes the header and all QSOs of the log, without END-OF-LOG. It resolves the configuration
+// for the QSOs that are appended later.
+func (w *LogWriter) writeAll(out io.Writer) error {
+	l, config, tags := w.writer.prepare(w.log)
+	config.alignQSOs = false
+	config.sortQSOs = false
+
+	err := writeHeader(out, l, config, tags)
+	if err != nil {
+		return err
+	}
+	err = writeQSOData(out, l, config)
+	if err != nil {
+		return err
+	}
+	w.config = config
+	return nil
+}
+
+// rewrite writes the whole log into a temporary file that atomically replaces the file, so the file never
+// contains a partial log.
+func (w *LogWriter) rewrite() error {
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(w.filename), "."+filepath.Base(w.filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	formerConfig := w.config
+	err = w.writeAll(w.writer.output(context.Background(), temp))
+	if err == nil {
+		err = temp.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	closeErr := temp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		w.config = formerConfig
+		return err
+	}
+
+	// the file is closed before the rename, since open files cannot be replaced on all platforms
+	w.file.Close()
+	renameErr := os.Rename(temp.Name(), w.filename)
+	if renameErr != nil {
+		w.config = formerConfig
+	}
+	file, err := os.OpenFile(w.filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	w.setFile(file)
+	return renameErr
+}
+
+// Log returns the log that was written so far. It must not be modified.
+func (w *LogWriter) Log() *Log {
+	return w.log
+}
+
+// Repairs returns the repairs that were necessary to recover the log when it was opened with OpenLogWriter.
+func (w *LogWriter) Repairs() []Repair {
+	return w.repairs
+}
+
+// WriteQSO appends the given QSO to the log.
+func (w *LogWriter) WriteQSO(qso QSO) error {
+	return w.appendQSO(QSOTag, qso, &w.log.QSOData)
+}
+
+// WriteIgnoredQSO appends the given QSO as X-QSO to the log.
+func (w *LogWriter) WriteIgnoredQSO(qso QSO) error {
+	return w.appendQSO(XQSOTag, qso, &w.log.IgnoredQSOs)
+}
+
+func (w *LogWriter) appendQSO(tag Tag, qso QSO, qsos *[]QSO) error {
+	if qso.Transmitter != 0 && !w.config.appendTX && w.writer.transmitter == TransmitterAuto {
+		// the former QSOs were written without the transmitter column, they need it from now on
+		*qsos = append(*qsos, qso)
+		err := w.rewrite()
+		if err != nil {
+			*qsos = (*qsos)[:len(*qsos)-1]
+		}
+		return err
+	}
+
+	written := qso
+	if w.config.transliterate {
+		written = transliteratedQSOs([]QSO{qso})[0]
+	}
+	err := writeQSO(w.out, tag, written, w.config, nil)
+	if err != nil {
+		return err
+	}
+	*qsos = append(*qsos, qso)
+	return w.file.Sync()
+}
+
+// UpdateHeader replaces the header of the log with the header of the given log, the QSO data of the given log
+// is ignored. The file is rewritten completely and atomically replaced, so it never contains a partial header.
+func (w *LogWriter) UpdateHeader(header *Log) error {
+	l := copyLog(header)
+	l.QSOData = w.log.QSOData
+	l.IgnoredQSOs = w.log.IgnoredQSOs
+
+	formerLog := w.log
+	w.log = l
+	err := w.rewrite()
+	if err != nil {
+		w.log = formerLog
+	}
+	return err
+}
+
+// Close writes END-OF-LOG and closes the file.
+func (w *LogWriter) Close() error {
+	err := writeRows(w.out, row{EndOfLogTag, "", false})
+	if err == nil {
+		err = w.file.Sync()
+	}
+	closeErr := w.file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// copyLog returns a copy of the given log that does not share the QSO data and the custom tags.
+func copyLog(l *Log) *Log {
+	result := *l
+	result.Custom = append(CustomTags{}, l.Custom...)
+	result.QSOData = append([]QSO{}, l.QSOData...)
+	result.IgnoredQSOs = append([]QSO{}, l.IgnoredQSOs...)
+	return &result
+}
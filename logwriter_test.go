@@ -0,0 +1,257 @@
+package cabrillo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logWriterTestHeader() *Log {
+	result := NewLog()
+	result.CabrilloVersion = "3.0"
+	result.Contest = "CQ-WW-CW"
+	result.Callsign = callsign.MustParse("DL1ABC")
+	return result
+}
+
+func logWriterTestQSO(minute int, call string) QSO {
+	return QSO{
+		Frequency: "14025",
+		Mode:      QSOModeCW,
+		Timestamp: time.Date(2024, time.October, 26, 0, minute, 0, 0, time.UTC),
+		Sent:      QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}},
+		Received:  QSOInfo{Call: callsign.MustParse(call), Exchange: []string{"599", "5"}},
+	}
+}
+
+func readFile(t *testing.T, filename string) string {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	return string(data)
+}
+
+const logWriterTestHeaderLines = "START-OF-LOG: 3.0\nCONTEST: CQ-WW-CW\nCALLSIGN: DL1ABC\nCLAIMED-SCORE: 0\nCERTIFICATE: NO\n"
+
+func TestLogWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "live.log")
+
+	writer, err := CreateLogWriter(filename, logWriterTestHeader())
+	require.NoError(t, err)
+	assert.Equal(t, logWriterTestHeaderLines, readFile(t, filename))
+
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(0, "W1AW")))
+	require.NoError(t, writer.WriteIgnoredQSO(logWriterTestQSO(1, "K1AR")))
+	assert.Equal(t, logWriterTestHeaderLines+
+		"QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5\n"+
+		"X-QSO: 14025 CW 2024-10-26 0001 DL1ABC 599 14 K1AR 599 5\n",
+		readFile(t, filename))
+
+	require.NoError(t, writer.Close())
+	log, err := Read(bytes.NewBufferString(readFile(t, filename)))
+	require.NoError(t, err)
+	assert.Len(t, log.QSOData, 1)
+	assert.Len(t, log.IgnoredQSOs, 1)
+}
+
+func TestCreateLogWriter_ExistingFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "live.log")
+	require.NoError(t, os.WriteFile(filename, []byte("existing"), 0o644))
+
+	_, err := CreateLogWriter(filename, logWriterTestHeader())
+
+	assert.ErrorIs(t, err, os.ErrExist)
+	assert.Equal(t, "existing", readFile(t, filename))
+}
+
+func TestOpenLogWriter(t *testing.T) {
+	const qsoLine = "QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5\n"
+	tests := []struct {
+		name            string
+		content         string
+		expected        string
+		expectedRepairs []Repair
+		invalid         bool
+	}{
+		{
+			name:     "closed log",
+			content:  logWriterTestHeaderLines + qsoLine + "END-OF-LOG:\n",
+			expected: logWriterTestHeaderLines + qsoLine,
+		},
+		{
+			name:     "crashed while writing a QSO",
+			content:  logWriterTestHeaderLines + qsoLine + "QSO: 14025 CW 2024-10-",
+			expected: logWriterTestHeaderLines + qsoLine,
+			expectedRepairs: []Repair{
+				{Line: 7, Kind: SkippedIncompleteLine, Message: "skipped the incomplete last line"},
+				{Line: 7, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"},
+			},
+		},
+		{
+			name:     "CRLF without trailing line break",
+			content:  "START-OF-LOG: 3.0\r\nCALLSIGN: DL1ABC\r\nEND-OF-LOG:",
+			expected: "START-OF-LOG: 3.0\r\nCALLSIGN: DL1ABC\r\n",
+		},
+		{
+			name:     "invalid QSO",
+			content:  logWriterTestHeaderLines + "QSO: 14025 CW\n" + qsoLine,
+			expected: logWriterTestHeaderLines + qsoLine,
+			expectedRepairs: []Repair{
+				{Line: 6, Kind: SkippedInvalidLine, Message: "skipped the invalid line: not enough QSO columns: 2"},
+				{Line: 7, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"},
+			},
+		},
+		{
+			name:     "lines after END-OF-LOG",
+			content:  logWriterTestHeaderLines + qsoLine + "END-OF-LOG:\n" + qsoLine,
+			expected: logWriterTestHeaderLines + qsoLine,
+			expectedRepairs: []Repair{
+				{Line: 8, Kind: IgnoredLineAfterEndOfLog, Message: "ignored the line after END-OF-LOG"},
+			},
+		},
+		{
+			name:    "no start",
+			content: "CALLSIGN: DL1ABC\n",
+			invalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "live.log")
+			require.NoError(t, os.WriteFile(filename, []byte(tt.content), 0o644))
+
+			writer, err := OpenLogWriter(filename)
+			if tt.invalid {
+				assert.Error(t, err)
+				assert.Equal(t, tt.content, readFile(t, filename), "an invalid file must not be modified")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, readFile(t, filename))
+			assert.Equal(t, tt.expectedRepairs, writer.Repairs())
+			assert.Equal(t, "DL1ABC", writer.Log().Callsign.String())
+
+			require.NoError(t, writer.WriteQSO(logWriterTestQSO(5, "JA1ABC")))
+			require.NoError(t, writer.Close())
+			appended := "QSO: 14025 CW 2024-10-26 0005 DL1ABC 599 14 JA1ABC 599 5\nEND-OF-LOG:\n"
+			if strings.Contains(tt.content, "\r\n") {
+				appended = strings.ReplaceAll(appended, "\n", "\r\n")
+			}
+			assert.Equal(t, tt.expected+appended, readFile(t, filename))
+		})
+	}
+}
+
+func TestLogWriter_TransmitterColumn(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "live.log")
+	writer, err := CreateLogWriter(filename, logWriterTestHeader())
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(0, "W1AW")))
+
+	qso := logWriterTestQSO(1, "K1AR")
+	qso.Transmitter = 1
+	require.NoError(t, writer.WriteQSO(qso))
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(2, "JA1ABC")))
+	require.NoError(t, writer.Close())
+
+	expected := logWriterTestHeaderLines +
+		"QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5 0\n" +
+		"QSO: 14025 CW 2024-10-26 0001 DL1ABC 599 14 K1AR 599 5 1\n" +
+		"QSO: 14025 CW 2024-10-26 0002 DL1ABC 599 14 JA1ABC 599 5 0\n" +
+		"END-OF-LOG:\n"
+	assert.Equal(t, expected, readFile(t, filename))
+}
+
+func TestLogWriter_UpdateHeader(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "live.log")
+	writer, err := CreateLogWriter(filename, logWriterTestHeader())
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(0, "W1AW")))
+
+	header := logWriterTestHeader()
+	header.Category.Transmitter = TwoTransmitter
+	header.QSOData = []QSO{logWriterTestQSO(1, "OH2XX")}
+	err = writer.UpdateHeader(header)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(2, "K1AR")))
+	require.NoError(t, writer.Close())
+
+	expected := "START-OF-LOG: 3.0\nCONTEST: CQ-WW-CW\nCALLSIGN: DL1ABC\nCLAIMED-SCORE: 0\nCATEGORY-TRANSMITTER: TWO\nCERTIFICATE: NO\n" +
+		"QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5 0\n" +
+		"QSO: 14025 CW 2024-10-26 0002 DL1ABC 599 14 K1AR 599 5 0\n" +
+		"END-OF-LOG:\n"
+	assert.Equal(t, expected, readFile(t, filename))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file must be removed")
+}
+
+func TestOpenLogWriter_TransmitterColumn(t *testing.T) {
+	const (
+		withColumn    = "QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5 0\n"
+		withoutColumn = "QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5\n"
+	)
+	tests := []struct {
+		name     string
+		content  string
+		options  []WriterOption
+		expected string
+	}{
+		{
+			name:     "keep the column",
+			content:  logWriterTestHeaderLines + withColumn,
+			expected: logWriterTestHeaderLines + withColumn + "QSO: 14025 CW 2024-10-26 0005 DL1ABC 599 14 JA1ABC 599 5 0\n",
+		},
+		{
+			name:     "keep no column",
+			content:  logWriterTestHeaderLines + withoutColumn,
+			expected: logWriterTestHeaderLines + withoutColumn + "QSO: 14025 CW 2024-10-26 0005 DL1ABC 599 14 JA1ABC 599 5\n",
+		},
+		{
+			name:     "conflicting option",
+			content:  logWriterTestHeaderLines + withColumn,
+			options:  []WriterOption{WithTransmitterColumn(TransmitterNever)},
+			expected: logWriterTestHeaderLines + withoutColumn + "QSO: 14025 CW 2024-10-26 0005 DL1ABC 599 14 JA1ABC 599 5\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "live.log")
+			require.NoError(t, os.WriteFile(filename, []byte(tt.content), 0o644))
+
+			writer, err := OpenLogWriter(filename, tt.options...)
+			require.NoError(t, err)
+			require.NoError(t, writer.WriteQSO(logWriterTestQSO(5, "JA1ABC")))
+			require.NoError(t, writer.Close())
+
+			assert.Equal(t, tt.expected+"END-OF-LOG:\n", readFile(t, filename))
+		})
+	}
+}
+
+func TestOpenLogWriter_CreatedWithTransmitterColumn(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "live.log")
+	writer, err := CreateLogWriter(filename, logWriterTestHeader(), WithTransmitterColumn(TransmitterAlways))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(0, "W1AW")))
+	require.NoError(t, writer.Close())
+
+	writer, err = OpenLogWriter(filename)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteQSO(logWriterTestQSO(1, "K1AR")))
+	require.NoError(t, writer.Close())
+
+	expected := logWriterTestHeaderLines +
+		"QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5 0\n" +
+		"QSO: 14025 CW 2024-10-26 0001 DL1ABC 599 14 K1AR 599 5 0\n" +
+		"END-OF-LOG:\n"
+	assert.Equal(t, expected, readFile(t, filename))
+}
@@ -2,6 +2,9 @@ package cabrillo
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
@@ -13,21 +16,91 @@ import (
 	"github.com/ftl/hamradio/locator"
 )
 
+// Read reads a log from r. Each line may be encoded in UTF-8 (with or without BOM), Latin-1 or Windows-1252,
+// see DetectCharset.
 func Read(r io.Reader) (*Log, error) {
-	result := NewLog()
-	parser := newParser(result)
+	return NewReader().Read(r)
+}
 
-	lineScanner := bufio.NewScanner(r)
-	for lineScanner.Scan() {
-		line := lineScanner.Text()
-		err := parser.AddLine(line)
-		if err != nil {
-			return nil, err
-		}
+// ReadContext reads a log from r like Read. It stops if the given context is done and returns
+// the error of the context, wrapped with the line that was reached.
+func ReadContext(ctx context.Context, r io.Reader) (*Log, error) {
+	return NewReader().ReadContext(ctx, r)
+}
+
+// Reader reads Cabrillo logs. Parsers for additional tags can be registered on a Reader
+// without affecting any other Reader.
+//
+// The limits protect against malicious input, e.g. on a public upload endpoint. A zero value
+// means no limit, except for MaxLineLength.
+type Reader struct {
+	// MaxLineLength is the maximum length of a single line in bytes. The default is DefaultMaxLineLength.
+	MaxLineLength int
+	// MaxQSOs is the maximum number of QSO and X-QSO lines.
+	MaxQSOs int
+	// MaxHeaderSize is the maximum size of all header lines, including custom tags, in bytes.
+	MaxHeaderSize int
+	// MaxBytes is the maximum size of the whole input in bytes.
+	MaxBytes int64
+
+	tagParsers map[Tag]tagParser
+}
+
+// DefaultMaxLineLength is the maximum line length if Reader.MaxLineLength is not set.
+const DefaultMaxLineLength = bufio.MaxScanTokenSize
+
+var (
+	ErrLineTooLong    = errors.New("the line is too long")
+	ErrTooManyQSOs    = errors.New("too many QSOs")
+	ErrHeaderTooLarge = errors.New("the header is too large")
+	ErrInputTooLarge  = errors.New("the input is too large")
+)
+
+// lineError is an error in a specific line of the input.
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
+func NewReader() *Reader {
+	return &Reader{
+		tagParsers: make(map[Tag]tagParser),
 	}
-	scanErr := lineScanner.Err()
-	if scanErr != nil {
-		return nil, scanErr
+}
+
+// RegisterTag registers the parse function for the given tag. The function is called with the value of
+// each line with this tag. It takes precedence over the builtin handling of the tag. Use SetExtension to
+// store the parsed value in the log.
+func (r *Reader) RegisterTag(tag Tag, parse func(l *Log, value string) error) {
+	if r.tagParsers == nil {
+		r.tagParsers = make(map[Tag]tagParser)
+	}
+	r.tagParsers[Tag(strings.ToUpper(string(tag)))] = tagParserFunc(parse)
+}
+
+func (r *Reader) Read(in io.Reader) (*Log, error) {
+	return r.ReadContext(context.Background(), in)
+}
+
+// ReadContext reads a log like Read. It stops if the given context is done and returns
+// the error of the context, wrapped with the line that was reached.
+func (r *Reader) ReadContext(ctx context.Context, in io.Reader) (*Log, error) {
+	result := NewLog()
+	parser := r.newParser(result)
+
+	err := r.scanLines(ctx, in, func(_ int, line string, _ bool) error {
+		return parser.AddLine(line)
+	})
+	if err != nil {
+		return nil, err
 	}
 	parserErr := parser.CheckComplete()
 	if parserErr != nil {
@@ -37,15 +110,104 @@ func Read(r io.Reader) (*Log, error) {
 	return result, nil
 }
 
+func (r *Reader) newParser(log *Log) *parser {
+	result := newParser(log)
+	result.extensions = r.tagParsers
+	result.maxQSOs = r.MaxQSOs
+	result.maxHeaderSize = r.MaxHeaderSize
+	return result
+}
+
+// scanLines calls handle for each line of the input, decoded into UTF-8. It applies the limits
+// of the Reader and checks the context between lines. Only the last line of the input may be incomplete,
+// i.e. without a line break at the end.
+func (r *Reader) scanLines(ctx context.Context, in io.Reader, handle func(lineNumber int, line string, complete bool) error) error {
+	var limited *limitedReader
+	if r.MaxBytes > 0 {
+		limited = &limitedReader{r: in, remaining: r.MaxBytes}
+		in = limited
+	}
+	maxLineLength := r.MaxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+
+	lineScanner := bufio.NewScanner(in)
+	// leave room for the line ending
+	lineScanner.Buffer(make([]byte, 0, min(maxLineLength+2, 4096)), maxLineLength+2)
+	lineNumber := 0
+	complete := true
+	lineScanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		// the last line was cut off by the limit, it must not reach the parser
+		if atEOF && limited != nil && limited.exceeded && bytes.IndexByte(data, '\n') == -1 {
+			return 0, nil, ErrInputTooLarge
+		}
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			complete = bytes.IndexByte(data[:advance], '\n') != -1
+		}
+		return advance, token, err
+	})
+	for lineScanner.Scan() {
+		lineNumber++
+		err := ctx.Err()
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		line := lineScanner.Bytes()
+		if len(line) > maxLineLength {
+			return fmt.Errorf("line %d: %w", lineNumber, ErrLineTooLong)
+		}
+		err = handle(lineNumber, decodeLine(line), complete)
+		if err != nil {
+			return err
+		}
+	}
+	scanErr := lineScanner.Err()
+	if errors.Is(scanErr, bufio.ErrTooLong) {
+		return fmt.Errorf("line %d: %w", lineNumber+1, ErrLineTooLong)
+	}
+	return scanErr
+}
+
+// limitedReader returns ErrInputTooLarge if the underlying reader provides more than the given number of bytes.
+// It records if the limit was exceeded, so that the last line, which may be incomplete, can be discarded.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrInputTooLarge
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		r.exceeded = true
+		return n, ErrInputTooLarge
+	}
+	return n, err
+}
+
 func newParser(log *Log) *parser {
 	return &parser{log: log}
 }
 
 type parser struct {
-	log        *Log
-	lineNumber int
-	started    bool
-	ended      bool
+	log           *Log
+	extensions    map[Tag]tagParser
+	maxQSOs       int
+	maxHeaderSize int
+	lineNumber    int
+	qsoCount      int
+	headerSize    int
+	started       bool
+	ended         bool
 }
 
 func (p *parser) AddLine(line string) error {
@@ -65,6 +227,18 @@ func (p *parser) AddLine(line string) error {
 	tag := Tag(strings.ToUpper(strings.TrimSpace(tagStr)))
 	value = strings.TrimSpace(value)
 
+	if tag == QSOTag || tag == XQSOTag {
+		p.qsoCount++
+		if p.maxQSOs > 0 && p.qsoCount > p.maxQSOs {
+			return &lineError{line: p.lineNumber, err: ErrTooManyQSOs}
+		}
+	} else {
+		p.headerSize += len(line)
+		if p.maxHeaderSize > 0 && p.headerSize > p.maxHeaderSize {
+			return &lineError{line: p.lineNumber, err: ErrHeaderTooLarge}
+		}
+	}
+
 	switch tag {
 	case StartOfLogTag:
 		if withinLog {
@@ -82,29 +256,26 @@ func (p *parser) AddLine(line string) error {
 }
 
 func (p *parser) lineErrorf(format string, args ...any) error {
-	prefix := fmt.Sprintf("line %d", p.lineNumber)
-	message := fmt.Sprintf(format, args...)
-	return fmt.Errorf("%s: %s", prefix, message)
+	return &lineError{line: p.lineNumber, err: fmt.Errorf(format, args...)}
 }
 
 func (p *parser) parseTag(tag Tag, value string) error {
-	tagParser, found := tagParsers[tag]
+	tagParser, found := p.extensions[tag]
 	if !found {
-		p.appendCustomValue(tag, value)
+		tagParser, found = tagParsers[tag]
+	}
+	if !found {
+		p.log.Custom.Add(tag, value)
 		return nil
 	}
-	return tagParser.Parse(p.log, value)
-}
-
-func (p *parser) appendCustomValue(tag Tag, value string) {
-	currentValue, found := p.log.Custom[tag]
-	var newValue string
-	if found {
-		newValue = currentValue + "\n" + value
-	} else {
-		newValue = value
+	err := tagParser.Parse(p.log, value)
+	if err != nil {
+		return err
 	}
-	p.log.Custom[tag] = newValue
+	if _, registered := p.extensions[tag]; registered {
+		p.log.extensionLines.Add(tag, value)
+	}
+	return nil
 }
 
 func (p *parser) CheckComplete() error {
@@ -236,7 +407,13 @@ var tagParsers = map[Tag]tagParser{
 		return nil
 	}),
 	AddressTag: tagParserFunc(func(log *Log, value string) error {
-		log.Address.Text = value
+		if value == "" {
+			return nil
+		}
+		if log.Address.Text != "" {
+			log.Address.Text += "\n"
+		}
+		log.Address.Text += value
 		return nil
 	}),
 	AddressCityTag: tagParserFunc(func(log *Log, value string) error {
@@ -257,7 +434,9 @@ var tagParsers = map[Tag]tagParser{
 	}),
 	OperatorsTag: tagParserFunc(func(log *Log, value string) error {
 		parts := operatorsSeparator.Split(value, -1)
-		operators := make([]callsign.Callsign, 0, len(parts))
+		if log.Operators == nil {
+			log.Operators = make([]callsign.Callsign, 0, len(parts))
+		}
 		for _, part := range parts {
 			if part == "" {
 				continue
@@ -271,12 +450,20 @@ var tagParsers = map[Tag]tagParser{
 			if err != nil {
 				return err
 			}
-			operators = append(operators, call)
-			if isHost {
-				log.Host = call
+			if !isHost {
+				log.Operators = append(log.Operators, call)
+				continue
+			}
+			if log.Host == call {
+				// the host is repeated, e.g. on each of several OPERATORS lines
+				continue
+			}
+			if log.Host != callsign.NoCallsign {
+				return fmt.Errorf("more than one host station: %s and %s", log.Host, call)
 			}
+			log.Operators = append(log.Operators, call)
+			log.Host = call
 		}
-		log.Operators = operators
 		return nil
 	}),
 	OfftimeTag: tagParserFunc(func(log *Log, value string) error {
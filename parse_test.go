@@ -3,6 +3,7 @@ package cabrillo
 import (
 	"bytes"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -65,6 +66,38 @@ func TestRead_FromStartToEnd(t *testing.T) {
 	}
 }
 
+func TestReader_Limits(t *testing.T) {
+	qso := "QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5\n"
+	input := "START-OF-LOG: 3.0\nCALLSIGN: DL1ABC\nX-NOTE: " + strings.Repeat("x", 100) + "\n" + qso + qso + "X-" + qso + "END-OF-LOG:\n"
+	tt := []struct {
+		desc     string
+		reader   Reader
+		value    string
+		expected error
+	}{
+		{desc: "no limits", reader: Reader{}, value: input},
+		{desc: "within all limits", reader: Reader{MaxLineLength: 120, MaxQSOs: 3, MaxHeaderSize: 200, MaxBytes: int64(len(input))}, value: input},
+		{desc: "line too long", reader: Reader{MaxLineLength: 80}, value: input, expected: ErrLineTooLong},
+		{desc: "line longer than the default", reader: Reader{}, value: "START-OF-LOG: 3.0\nX-NOTE: " + strings.Repeat("x", DefaultMaxLineLength) + "\nEND-OF-LOG:\n", expected: ErrLineTooLong},
+		{desc: "too many QSOs", reader: Reader{MaxQSOs: 2}, value: input, expected: ErrTooManyQSOs},
+		{desc: "header too large", reader: Reader{MaxHeaderSize: 100}, value: input, expected: ErrHeaderTooLarge},
+		{desc: "input too large", reader: Reader{MaxBytes: int64(len(input)) - 1}, value: input, expected: ErrInputTooLarge},
+		{desc: "input cut inside a QSO line", reader: Reader{MaxBytes: int64(strings.Index(input, "X-QSO") - 10)}, value: input, expected: ErrInputTooLarge},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			log, err := tc.reader.Read(strings.NewReader(tc.value))
+
+			if tc.expected == nil {
+				require.NoError(t, err)
+				assert.Len(t, log.QSOData, 2)
+			} else {
+				assert.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+}
+
 func TestParser_ParseAllTags(t *testing.T) {
 	lines := []string{
 		"START-OF-LOG: 3.0",
@@ -88,6 +121,7 @@ func TestParser_ParseAllTags(t *testing.T) {
 		"LOCATION: DX",
 		"NAME: Constantin Valberg",
 		"ADDRESS: beside the big river",
+		"ADDRESS: second house on the left",
 		"ADDRESS-CITY: Musterstadt",
 		"ADDRESS-STATE-PROVINCE: Bavaria",
 		"ADDRESS-POSTALCODE: 80123",
@@ -133,7 +167,7 @@ func TestParser_ParseAllTags(t *testing.T) {
 	assert.Equal(t, locator.MustParse("JN59"), actualLog.GridLocator, "grid locator")
 	assert.Equal(t, "DX", actualLog.Location, "location")
 	assert.Equal(t, "Constantin Valberg", actualLog.Name, "name")
-	assert.Equal(t, "beside the big river", actualLog.Address.Text, "address text")
+	assert.Equal(t, "beside the big river\nsecond house on the left", actualLog.Address.Text, "address text")
 	assert.Equal(t, "Musterstadt", actualLog.Address.City, "address city")
 	assert.Equal(t, "Bavaria", actualLog.Address.StateProvince, "address state/province")
 	assert.Equal(t, "80123", actualLog.Address.Postalcode, "address postalcode")
@@ -216,6 +250,17 @@ func TestParseOperators(t *testing.T) {
 			expectedOperators: []string{"DL1ABC", "DL2ABC", "DL3ABC", "DL4ABC"},
 			expectedHost:      "DL4ABC",
 		},
+		{
+			desc:    "multiple hosts",
+			value:   "@DL1ABC, DL2ABC, @DL3ABC",
+			invalid: true,
+		},
+		{
+			desc:              "repeated host",
+			value:             "@DL1ABC, DL2ABC, @DL1ABC",
+			expectedOperators: []string{"DL1ABC", "DL2ABC"},
+			expectedHost:      "DL1ABC",
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -241,6 +286,35 @@ func TestParseOperators(t *testing.T) {
 	}
 }
 
+func TestParseOperators_Accumulate(t *testing.T) {
+	log := NewLog()
+	parser := newParser(log)
+	lines := []string{
+		"START-OF-LOG: 3.0",
+		"OPERATORS: @DL1ABC DL2ABC",
+		"OPERATORS: DL3ABC, DL4ABC",
+		"END-OF-LOG:",
+	}
+	for _, line := range lines {
+		require.NoError(t, parser.AddLine(line))
+	}
+
+	expected := []callsign.Callsign{
+		callsign.MustParse("DL1ABC"),
+		callsign.MustParse("DL2ABC"),
+		callsign.MustParse("DL3ABC"),
+		callsign.MustParse("DL4ABC"),
+	}
+	assert.Equal(t, expected, log.Operators)
+	assert.Equal(t, callsign.MustParse("DL1ABC"), log.Host)
+
+	require.NoError(t, parser.AddLine("OPERATORS: @DL1ABC DL5ABC"))
+	assert.Equal(t, append(expected, callsign.MustParse("DL5ABC")), log.Operators)
+
+	err := parser.AddLine("OPERATORS: @DL6ABC")
+	assert.Error(t, err, "second host")
+}
+
 func TestParseOfftime(t *testing.T) {
 	tt := []struct {
 		desc          string
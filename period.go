@@ -0,0 +1,117 @@
+package cabrillo
+
+import (
+	"strings"
+	"time"
+)
+
+// Period is the time period of a contest. Start is inclusive, End is exclusive.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (p Period) IsZero() bool {
+	return p.Start.IsZero() && p.End.IsZero()
+}
+
+// Contains indicates if the given point in time is within the period.
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// periodRule calculates the period of a contest in a given year.
+type periodRule func(year int) Period
+
+// fullWeekend returns a rule for contests that start on saturday 0000z of the n-th full weekend of the given month.
+// A negative n counts from the end of the month.
+func fullWeekend(month time.Month, n int, duration time.Duration) periodRule {
+	return func(year int) Period {
+		var saturdays []time.Time
+		for day := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC); day.Month() == month; day = day.AddDate(0, 0, 1) {
+			sunday := day.AddDate(0, 0, 1)
+			if day.Weekday() == time.Saturday && sunday.Month() == month {
+				saturdays = append(saturdays, day)
+			}
+		}
+		var start time.Time
+		if n < 0 {
+			start = saturdays[len(saturdays)+n]
+		} else {
+			start = saturdays[n-1]
+		}
+		return Period{Start: start, End: start.Add(duration)}
+	}
+}
+
+var knownPeriods = map[ContestIdentifier]periodRule{
+	"CQ-WW-CW":    fullWeekend(time.November, -1, 48*time.Hour),
+	"CQ-WW-SSB":   fullWeekend(time.October, -1, 48*time.Hour),
+	"CQ-WW-RTTY":  fullWeekend(time.September, -1, 48*time.Hour),
+	"CQ-WPX-CW":   fullWeekend(time.May, -1, 48*time.Hour),
+	"CQ-WPX-SSB":  fullWeekend(time.March, -1, 48*time.Hour),
+	"CQ-WPX-RTTY": fullWeekend(time.February, 2, 48*time.Hour),
+	"ARRL-DX-CW":  fullWeekend(time.February, 3, 48*time.Hour),
+	"ARRL-DX-SSB": fullWeekend(time.March, 1, 48*time.Hour),
+}
+
+// PeriodFor returns the period of a known contest in the given year.
+func PeriodFor(contest ContestIdentifier, year int) (Period, bool) {
+	rule, ok := knownPeriods[ContestIdentifier(strings.ToUpper(strings.TrimSpace(string(contest))))]
+	if !ok {
+		return Period{}, false
+	}
+	return rule(year), true
+}
+
+// DetectPeriod sets the period of the given log from the period of the contest in the year of the first QSO.
+// The result is false if the contest is unknown or the log contains no QSOs.
+func DetectPeriod(l *Log) bool {
+	if len(l.QSOData) == 0 {
+		return false
+	}
+	period, ok := PeriodFor(l.Contest, l.QSOData[0].Timestamp.UTC().Year())
+	if !ok {
+		return false
+	}
+	l.Period = period
+	return true
+}
+
+// CheckPeriod reports all QSOs that were made before or after the period of the given log.
+// The result is empty if the log has no period.
+func CheckPeriod(l *Log) []Problem {
+	if l.Period.IsZero() {
+		return nil
+	}
+	var result []Problem
+	for i, qso := range l.QSOData {
+		switch {
+		case qso.Timestamp.Before(l.Period.Start):
+			result = append(result, qsoProblem(i, "the QSO was made before the contest started at %s", formatTimestamp(l.Period.Start)))
+		case !qso.Timestamp.Before(l.Period.End):
+			result = append(result, qsoProblem(i, "the QSO was made after the contest ended at %s", formatTimestamp(l.Period.End)))
+		}
+	}
+	return result
+}
+
+// IgnoreQSOsOutsidePeriod moves all QSOs that were made outside of the period of the given log from QSOData to
+// IgnoredQSOs, so they are written as X-QSO. It returns the number of moved QSOs.
+func IgnoreQSOsOutsidePeriod(l *Log) int {
+	if l.Period.IsZero() {
+		return 0
+	}
+	qsoData := make([]QSO, 0, len(l.QSOData))
+	moved := 0
+	for _, qso := range l.QSOData {
+		if l.Period.Contains(qso.Timestamp) {
+			qsoData = append(qsoData, qso)
+			continue
+		}
+		l.IgnoredQSOs = append(l.IgnoredQSOs, qso)
+		moved++
+	}
+	l.QSOData = qsoData
+	return moved
+}
@@ -0,0 +1,59 @@
+package cabrillo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodFor(t *testing.T) {
+	tt := []struct {
+		contest       ContestIdentifier
+		year          int
+		expectedStart time.Time
+	}{
+		{"CQ-WW-CW", 2024, time.Date(2024, time.November, 23, 0, 0, 0, 0, time.UTC)},
+		{"CQ-WW-SSB", 2024, time.Date(2024, time.October, 26, 0, 0, 0, 0, time.UTC)},
+		{"CQ-WW-SSB", 2000, time.Date(2000, time.October, 28, 0, 0, 0, 0, time.UTC)},
+		{"CQ-WPX-SSB", 2025, time.Date(2025, time.March, 29, 0, 0, 0, 0, time.UTC)},
+		{"CQ-WPX-CW", 2020, time.Date(2020, time.May, 30, 0, 0, 0, 0, time.UTC)},
+		{"ARRL-DX-CW", 2025, time.Date(2025, time.February, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tt {
+		t.Run(string(tc.contest), func(t *testing.T) {
+			period, ok := PeriodFor(tc.contest, tc.year)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expectedStart, period.Start)
+			assert.Equal(t, tc.expectedStart.Add(48*time.Hour), period.End)
+		})
+	}
+
+	_, ok := PeriodFor("UNKNOWN", 2024)
+	assert.False(t, ok)
+}
+
+func TestIgnoreQSOsOutsidePeriod(t *testing.T) {
+	start := time.Date(2024, time.November, 23, 0, 0, 0, 0, time.UTC)
+	log := NewLog()
+	log.Contest = "CQ-WW-CW"
+	log.QSOData = []QSO{
+		{Frequency: "14025", Timestamp: start.Add(-time.Minute)},
+		{Frequency: "14026", Timestamp: start},
+		{Frequency: "14027", Timestamp: start.Add(47 * time.Hour)},
+		{Frequency: "14028", Timestamp: start.Add(48 * time.Hour)},
+	}
+
+	assert.True(t, DetectPeriod(log))
+	assert.Equal(t, []Problem{
+		{Tag: QSOTag, QSO: 0, Message: "the QSO was made before the contest started at 2024-11-23 0000"},
+		{Tag: QSOTag, QSO: 3, Message: "the QSO was made after the contest ended at 2024-11-25 0000"},
+	}, CheckPeriod(log))
+
+	moved := IgnoreQSOsOutsidePeriod(log)
+
+	assert.Equal(t, 2, moved)
+	assert.Equal(t, []QSOFrequency{"14026", "14027"}, []QSOFrequency{log.QSOData[0].Frequency, log.QSOData[1].Frequency})
+	assert.Equal(t, []QSOFrequency{"14025", "14028"}, []QSOFrequency{log.IgnoredQSOs[0].Frequency, log.IgnoredQSOs[1].Frequency})
+	assert.Empty(t, CheckPeriod(log))
+}
@@ -0,0 +1,173 @@
+package cabrillo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Repair describes a change that was necessary to recover a log. Line is the line in the input.
+type Repair struct {
+	Line    int
+	Kind    RepairKind
+	Message string
+}
+
+// RepairKind identifies the kind of a repair.
+type RepairKind int
+
+const (
+	// SkippedInvalidLine indicates that an invalid line was skipped.
+	SkippedInvalidLine RepairKind = iota
+	// SkippedIncompleteLine indicates that the last line was skipped, because it has no line break at the end.
+	SkippedIncompleteLine
+	// AddedEndOfLog indicates that the missing END-OF-LOG was added.
+	AddedEndOfLog
+	// IgnoredLineAfterEndOfLog indicates that a line after END-OF-LOG was ignored.
+	IgnoredLineAfterEndOfLog
+	// IgnoredLogs indicates that more logs in the input were ignored.
+	IgnoredLogs
+)
+
+func (r Repair) String() string {
+	return fmt.Sprintf("line %d: %s", r.Line, r.Message)
+}
+
+// RecoveredLog is a log that was read from a START-OF-LOG/END-OF-LOG block of a file with several logs.
+// Line is the line of the START-OF-LOG tag in the input. Repairs is empty if the log was read strictly.
+type RecoveredLog struct {
+	Log     *Log
+	Line    int
+	Repairs []Repair
+}
+
+// ReadAll reads all logs from r, see Reader.ReadAll.
+func ReadAll(r io.Reader) ([]*Log, error) {
+	return NewReader().ReadAll(r)
+}
+
+// ReadRecover reads a log from r and salvages as much as possible, see Reader.ReadRecover.
+func ReadRecover(r io.Reader) (*Log, []Repair, error) {
+	return NewReader().ReadRecover(r)
+}
+
+// ReadAllRecover reads all logs from r and salvages as much as possible, see Reader.ReadAllRecover.
+func ReadAllRecover(r io.Reader) ([]RecoveredLog, error) {
+	return NewReader().ReadAllRecover(r)
+}
+
+// ReadAll reads all logs from the input, one log for each START-OF-LOG/END-OF-LOG block, e.g. from a file
+// that contains several concatenated logs. Lines outside of the blocks are ignored. Each log must be valid,
+// like with Read.
+func (r *Reader) ReadAll(in io.Reader) ([]*Log, error) {
+	logs, err := r.readLogs(context.Background(), in, false)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Log, len(logs))
+	for i, l := range logs {
+		result[i] = l.Log
+	}
+	return result, nil
+}
+
+// ReadRecover reads a log like Read, but it salvages as much as possible from damaged input, e.g. from
+// a file that was truncated by a crashed logger: invalid lines are skipped, as well as a last line without
+// a line break, which was probably cut off even if it looks valid, and a missing END-OF-LOG is added. All repairs are reported. If the input contains several logs, only
+// the first one is returned, use ReadAllRecover to get all of them. The limits of the Reader still apply.
+func (r *Reader) ReadRecover(in io.Reader) (*Log, []Repair, error) {
+	logs, err := r.readLogs(context.Background(), in, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := logs[0]
+	if len(logs) > 1 {
+		result.Repairs = append(result.Repairs, Repair{Line: logs[1].Line, Kind: IgnoredLogs, Message: fmt.Sprintf("ignored %d more logs", len(logs)-1)})
+	}
+	return result.Log, result.Repairs, nil
+}
+
+// ReadAllRecover reads all logs from the input like ReadAll, but it salvages as much as possible from each log,
+// like ReadRecover. A START-OF-LOG within a log that is not yet complete ends the former log.
+func (r *Reader) ReadAllRecover(in io.Reader) ([]RecoveredLog, error) {
+	return r.readLogs(context.Background(), in, true)
+}
+
+func (r *Reader) readLogs(ctx context.Context, in io.Reader, lenient bool) ([]RecoveredLog, error) {
+	var result []RecoveredLog
+	var current *RecoveredLog
+	var parser *parser
+	finish := func(lineNumber int) {
+		if !parser.ended {
+			current.Repairs = append(current.Repairs, Repair{Line: lineNumber, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"})
+		}
+		result = append(result, *current)
+		current = nil
+		parser = nil
+	}
+
+	lastLine := 0
+	err := r.scanLines(ctx, in, func(lineNumber int, line string, complete bool) error {
+		lastLine = lineNumber
+		tagStr, _, _ := strings.Cut(line, ":")
+		if Tag(strings.ToUpper(strings.TrimSpace(tagStr))) == StartOfLogTag {
+			if current != nil {
+				if !lenient {
+					return fmt.Errorf("line %d: the log already started in a former line", lineNumber)
+				}
+				finish(lineNumber)
+			}
+			current = &RecoveredLog{Log: NewLog(), Line: lineNumber}
+			parser = r.newParser(current.Log)
+		}
+		if current == nil {
+			// ignore any lines outside the start and end tags, but report them after a log
+			if lenient && len(result) > 0 && strings.TrimSpace(line) != "" {
+				last := &result[len(result)-1]
+				last.Repairs = append(last.Repairs, Repair{Line: lineNumber, Kind: IgnoredLineAfterEndOfLog, Message: "ignored the line after END-OF-LOG"})
+			}
+			return nil
+		}
+
+		if lenient && !complete && Tag(strings.ToUpper(strings.TrimSpace(tagStr))) != EndOfLogTag {
+			// the last line was probably cut off while it was written, even if it looks valid
+			current.Repairs = append(current.Repairs, Repair{Line: lineNumber, Kind: SkippedIncompleteLine, Message: "skipped the incomplete last line"})
+			return nil
+		}
+
+		parser.lineNumber = lineNumber - 1
+		err := parser.AddLine(line)
+		var lineErr *lineError
+		if err != nil && !errors.As(err, &lineErr) {
+			lineErr = &lineError{line: lineNumber, err: err}
+		}
+		switch {
+		case err == nil:
+		case !lenient, errors.Is(err, ErrTooManyQSOs), errors.Is(err, ErrHeaderTooLarge):
+			return lineErr
+		default:
+			current.Repairs = append(current.Repairs, Repair{Line: lineNumber, Kind: SkippedInvalidLine, Message: "skipped the invalid line: " + lineErr.err.Error()})
+		}
+
+		if parser.ended {
+			finish(lineNumber)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		if !lenient {
+			return nil, fmt.Errorf("no END-OF-LOG tag found")
+		}
+		finish(lastLine)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no START-OF-LOG tag found")
+	}
+	return result, nil
+}
@@ -0,0 +1,192 @@
+package cabrillo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	recoverTestQSO1 = "QSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 W1AW 599 5\n"
+	recoverTestQSO2 = "QSO: 14026 CW 2024-10-26 0001 DL1ABC 599 14 K1AR 599 5\n"
+)
+
+func TestReadRecover(t *testing.T) {
+	tests := []struct {
+		name            string
+		value           string
+		expectedQSOs    int
+		expectedRepairs []Repair
+		invalid         bool
+	}{
+		{
+			name:         "valid log",
+			value:        "START-OF-LOG: 3.0\n" + recoverTestQSO1 + recoverTestQSO2 + "END-OF-LOG:\n",
+			expectedQSOs: 2,
+		},
+		{
+			name:            "missing END-OF-LOG",
+			value:           "START-OF-LOG: 3.0\n" + recoverTestQSO1 + recoverTestQSO2,
+			expectedQSOs:    2,
+			expectedRepairs: []Repair{{Line: 3, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"}},
+		},
+		{
+			name:         "half-written last QSO",
+			value:        "START-OF-LOG: 3.0\n" + recoverTestQSO1 + "QSO: 14026 CW 2024-10-26 00",
+			expectedQSOs: 1,
+			expectedRepairs: []Repair{
+				{Line: 3, Kind: SkippedIncompleteLine, Message: "skipped the incomplete last line"},
+				{Line: 3, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"},
+			},
+		},
+		{
+			name:         "cut-off last QSO with enough columns",
+			value:        "START-OF-LOG: 3.0\n" + recoverTestQSO1 + "QSO: 14026 CW 2024-10-26 0001 DL1ABC 599 14 K1AR 599 5",
+			expectedQSOs: 1,
+			expectedRepairs: []Repair{
+				{Line: 3, Kind: SkippedIncompleteLine, Message: "skipped the incomplete last line"},
+				{Line: 3, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"},
+			},
+		},
+		{
+			name:         "END-OF-LOG without line break",
+			value:        "START-OF-LOG: 3.0\n" + recoverTestQSO1 + recoverTestQSO2 + "END-OF-LOG:",
+			expectedQSOs: 2,
+		},
+		{
+			name:         "invalid header value",
+			value:        "START-OF-LOG: 3.0\nCLAIMED-SCORE: many\n" + recoverTestQSO1 + "END-OF-LOG:\n",
+			expectedQSOs: 1,
+			expectedRepairs: []Repair{
+				{Line: 2, Kind: SkippedInvalidLine, Message: "skipped the invalid line: strconv.Atoi: parsing \"many\": invalid syntax"},
+			},
+		},
+		{
+			name:         "garbage within the log",
+			value:        "START-OF-LOG: 3.0\nthis is no Cabrillo line\n" + recoverTestQSO1 + "END-OF-LOG:\n",
+			expectedQSOs: 1,
+			expectedRepairs: []Repair{
+				{Line: 2, Kind: SkippedInvalidLine, Message: "skipped the invalid line: this is no Cabrillo line is not a valid Cabrillo log line"},
+			},
+		},
+		{
+			name:         "concatenated logs",
+			value:        "START-OF-LOG: 3.0\n" + recoverTestQSO1 + "START-OF-LOG: 3.0\n" + recoverTestQSO1 + recoverTestQSO2 + "END-OF-LOG:\n",
+			expectedQSOs: 1,
+			expectedRepairs: []Repair{
+				{Line: 3, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"},
+				{Line: 3, Kind: IgnoredLogs, Message: "ignored 1 more logs"},
+			},
+		},
+		{
+			name:            "lines after END-OF-LOG",
+			value:           "START-OF-LOG: 3.0\n" + recoverTestQSO1 + "END-OF-LOG:\n\n" + recoverTestQSO2,
+			expectedQSOs:    1,
+			expectedRepairs: []Repair{{Line: 5, Kind: IgnoredLineAfterEndOfLog, Message: "ignored the line after END-OF-LOG"}},
+		},
+		{
+			name:    "no START-OF-LOG",
+			value:   recoverTestQSO1 + "END-OF-LOG:\n",
+			invalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, repairs, err := ReadRecover(strings.NewReader(tt.value))
+			if tt.invalid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, log.QSOData, tt.expectedQSOs)
+			assert.Equal(t, tt.expectedRepairs, repairs)
+		})
+	}
+}
+
+func TestReadRecover_LimitsApply(t *testing.T) {
+	reader := NewReader()
+	reader.MaxQSOs = 1
+
+	_, _, err := reader.ReadRecover(strings.NewReader("START-OF-LOG: 3.0\n" + recoverTestQSO1 + recoverTestQSO2))
+
+	assert.ErrorIs(t, err, ErrTooManyQSOs)
+}
+
+func TestReadAll(t *testing.T) {
+	value := "From: dl1abc@example.com\nSubject: my logs\n\n" +
+		"START-OF-LOG: 3.0\nCALLSIGN: DL1ABC\n" + recoverTestQSO1 + "END-OF-LOG:\n" +
+		"-- \nsignature: of the email\n" +
+		"START-OF-LOG: 3.0\nCALLSIGN: DL2XYZ\n" + recoverTestQSO1 + recoverTestQSO2 + "END-OF-LOG:\n"
+
+	logs, err := ReadAll(strings.NewReader(value))
+	require.NoError(t, err)
+
+	require.Len(t, logs, 2)
+	assert.Equal(t, "DL1ABC", logs[0].Callsign.String())
+	assert.Len(t, logs[0].QSOData, 1)
+	assert.Empty(t, logs[0].Custom)
+	assert.Equal(t, "DL2XYZ", logs[1].Callsign.String())
+	assert.Len(t, logs[1].QSOData, 2)
+	assert.Empty(t, logs[1].Custom)
+}
+
+func TestReadAll_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "missing END-OF-LOG",
+			value:    "START-OF-LOG: 3.0\n" + recoverTestQSO1 + "START-OF-LOG: 3.0\nEND-OF-LOG:\n",
+			expected: "line 3: the log already started in a former line",
+		},
+		{
+			name:     "invalid QSO",
+			value:    "START-OF-LOG: 3.0\nEND-OF-LOG:\nSTART-OF-LOG: 3.0\nQSO: 14025 CW\nEND-OF-LOG:\n",
+			expected: "line 4: not enough QSO columns: 2",
+		},
+		{
+			name:     "truncated",
+			value:    "START-OF-LOG: 3.0\nEND-OF-LOG:\nSTART-OF-LOG: 3.0\n",
+			expected: "no END-OF-LOG tag found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAll(strings.NewReader(tt.value))
+
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestReadAllRecover(t *testing.T) {
+	value := "START-OF-LOG: 3.0\nCALLSIGN: DL1ABC\n" + recoverTestQSO1 +
+		"START-OF-LOG: 3.0\nCALLSIGN: DL2XYZ\n" + recoverTestQSO1 + "QSO: 14026 CW 2024-10-26 00"
+
+	logs, err := ReadAllRecover(strings.NewReader(value))
+	require.NoError(t, err)
+
+	require.Len(t, logs, 2)
+	assert.Equal(t, "DL1ABC", logs[0].Log.Callsign.String())
+	assert.Equal(t, 1, logs[0].Line)
+	assert.Equal(t, []Repair{{Line: 4, Kind: AddedEndOfLog, Message: "added the missing END-OF-LOG"}}, logs[0].Repairs)
+	assert.Equal(t, "DL2XYZ", logs[1].Log.Callsign.String())
+	assert.Equal(t, 4, logs[1].Line)
+	assert.Len(t, logs[1].Log.QSOData, 1)
+	assert.Len(t, logs[1].Repairs, 2)
+}
+
+func TestRead_TagErrorsKeepTheirText(t *testing.T) {
+	value := "START-OF-LOG: 3.0\nCLAIMED-SCORE: many\nEND-OF-LOG:\n"
+
+	_, err := Read(strings.NewReader(value))
+	assert.EqualError(t, err, `strconv.Atoi: parsing "many": invalid syntax`)
+
+	_, err = ReadAll(strings.NewReader(value))
+	assert.EqualError(t, err, `line 2: strconv.Atoi: parsing "many": invalid syntax`)
+}
@@ -0,0 +1,179 @@
+package cabrillo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// RedactionPolicy defines how a value is redacted.
+type RedactionPolicy int
+
+const (
+	// KeepValue leaves the value as it is.
+	KeepValue RedactionPolicy = iota
+	// DropValue removes the value completely.
+	DropValue
+	// HashValue replaces the value with a short hash. Equal values result in equal hashes, so values
+	// can still be correlated without revealing them.
+	HashValue
+	// MaskValue keeps the first character of each word and replaces all other letters and digits with '*'.
+	MaskValue
+)
+
+// hashLength is the number of hex digits of a hashed value.
+const hashLength = 16
+
+// Redaction removes personal data from a log before it is published. The policies apply to the header tags
+// with free text values (see RedactableTags), to custom tags and to the extension values of registered tags.
+// Multi-line values are redacted line by line.
+type Redaction struct {
+	Policies map[Tag]RedactionPolicy
+	// CustomPolicy applies to custom tags and extension values that have no policy in Policies. Extension
+	// values that are not strings cannot be hashed or masked, they are dropped instead.
+	CustomPolicy RedactionPolicy
+	// Salt is prepended to each value before it is hashed, this prevents to find the original values
+	// by simply hashing guessed values.
+	Salt string
+}
+
+// RedactableTags are the builtin tags that can be redacted. Any custom tag can be redacted as well.
+var RedactableTags = []Tag{
+	CreatedByTag, ClubTag, LocationTag, NameTag, EmailTag, AddressTag, AddressCityTag,
+	AddressStateProvinceTag, AddressPostalcodeTag, AddressCountryTag, SoapboxTag,
+}
+
+// PublicRedaction returns a redaction that drops the name, the email address and the postal address,
+// except the country. The soapbox is kept, since it is usually meant to be published. All custom tags
+// and extension values are dropped, unless a policy is added for them explicitly.
+func PublicRedaction() *Redaction {
+	return &Redaction{
+		CustomPolicy: DropValue,
+		Policies: map[Tag]RedactionPolicy{
+			NameTag:                 DropValue,
+			EmailTag:                DropValue,
+			AddressTag:              DropValue,
+			AddressCityTag:          DropValue,
+			AddressStateProvinceTag: DropValue,
+			AddressPostalcodeTag:    DropValue,
+		},
+	}
+}
+
+// Redact returns a copy of the given log with all values redacted according to the policies.
+// The given log is not modified.
+func (r *Redaction) Redact(l *Log) *Log {
+	result := *l
+
+	for tag, field := range redactableFields(&result) {
+		*field = r.redactLines(r.Policies[tag], *field)
+	}
+
+	result.Custom = make(CustomTags, 0, len(l.Custom))
+	for _, value := range l.Custom {
+		policy := r.customPolicy(value.Tag)
+		if policy == DropValue {
+			continue
+		}
+		result.Custom = append(result.Custom, CustomValue{Tag: value.Tag, Value: r.redactLines(policy, value.Value)})
+	}
+
+	result.extensionLines = make(CustomTags, 0, len(l.extensionLines))
+	for _, line := range l.extensionLines {
+		policy := r.customPolicy(line.Tag)
+		_, isText := l.Extensions[line.Tag].(string)
+		switch {
+		case policy == KeepValue:
+			result.extensionLines = append(result.extensionLines, line)
+		case policy != DropValue && isText:
+			result.extensionLines = append(result.extensionLines, CustomValue{Tag: line.Tag, Value: r.redactLines(policy, line.Value)})
+		}
+	}
+
+	if l.Extensions != nil {
+		result.Extensions = make(Extensions, len(l.Extensions))
+		for tag, value := range l.Extensions {
+			policy := r.customPolicy(tag)
+			text, isText := value.(string)
+			switch {
+			case policy == KeepValue:
+				result.Extensions[tag] = value
+			case policy != DropValue && isText:
+				result.Extensions[tag] = r.redactLines(policy, text)
+			}
+		}
+	}
+
+	return &result
+}
+
+func (r *Redaction) customPolicy(tag Tag) RedactionPolicy {
+	policy, ok := r.Policies[tag]
+	if !ok {
+		return r.CustomPolicy
+	}
+	return policy
+}
+
+func redactableFields(l *Log) map[Tag]*string {
+	return map[Tag]*string{
+		CreatedByTag:            &l.CreatedBy,
+		ClubTag:                 &l.Club,
+		LocationTag:             &l.Location,
+		NameTag:                 &l.Name,
+		EmailTag:                &l.Email,
+		AddressTag:              &l.Address.Text,
+		AddressCityTag:          &l.Address.City,
+		AddressStateProvinceTag: &l.Address.StateProvince,
+		AddressPostalcodeTag:    &l.Address.Postalcode,
+		AddressCountryTag:       &l.Address.Country,
+		SoapboxTag:              &l.Soapbox,
+	}
+}
+
+func (r *Redaction) redactLines(policy RedactionPolicy, value string) string {
+	switch policy {
+	case KeepValue:
+		return value
+	case DropValue:
+		return ""
+	}
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		lines[i] = r.redactValue(policy, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *Redaction) redactValue(policy RedactionPolicy, value string) string {
+	if value == "" {
+		return ""
+	}
+	switch policy {
+	case HashValue:
+		hash := sha256.Sum256([]byte(r.Salt + value))
+		return hex.EncodeToString(hash[:])[:hashLength]
+	case MaskValue:
+		return maskValue(value)
+	default:
+		return value
+	}
+}
+
+func maskValue(value string) string {
+	var b strings.Builder
+	startOfWord := true
+	for _, c := range value {
+		switch {
+		case unicode.IsSpace(c):
+			startOfWord = true
+		case startOfWord:
+			startOfWord = false
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			c = '*'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package cabrillo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func redactionTestLog() *Log {
+	l := NewLog()
+	l.CabrilloVersion = "3.0"
+	l.Name = "Hans Meier"
+	l.Email = "dl1abc@example.com"
+	l.Club = "Contest Club"
+	l.Address = Address{Text: "Hauptstr. 1\nc/o Meier", City: "Berlin", Postalcode: "10115", Country: "Germany"}
+	l.Soapbox = "Great fun!"
+	l.Custom = CustomTags{{"X-PHONE", "+49 30 123456"}, {"X-NOTE", "hello"}, {"X-PHONE", "+49 171 654321"}}
+	return l
+}
+
+func TestRedaction_Redact(t *testing.T) {
+	l := redactionTestLog()
+	redaction := &Redaction{
+		Policies: map[Tag]RedactionPolicy{
+			NameTag:    MaskValue,
+			EmailTag:   HashValue,
+			AddressTag: MaskValue,
+			ClubTag:    KeepValue,
+			"X-PHONE":  DropValue,
+			"X-NOTE":   MaskValue,
+		},
+	}
+
+	actual := redaction.Redact(l)
+
+	assert.Equal(t, "H*** M****", actual.Name)
+	assert.Len(t, actual.Email, hashLength)
+	assert.NotEqual(t, l.Email, actual.Email)
+	assert.Equal(t, "H*******. 1\nc/* M****", actual.Address.Text)
+	assert.Equal(t, "Berlin", actual.Address.City)
+	assert.Equal(t, "Contest Club", actual.Club)
+	assert.Equal(t, CustomTags{{"X-NOTE", "h****"}}, actual.Custom)
+
+	assert.Equal(t, "Hans Meier", l.Name, "the original log must not be modified")
+	assert.Len(t, l.Custom, 3, "the original custom tags must not be modified")
+}
+
+func TestRedaction_HashWithSalt(t *testing.T) {
+	l := redactionTestLog()
+	unsalted := &Redaction{Policies: map[Tag]RedactionPolicy{EmailTag: HashValue}}
+	salted := &Redaction{Policies: map[Tag]RedactionPolicy{EmailTag: HashValue}, Salt: "secret"}
+
+	assert.Equal(t, unsalted.Redact(l).Email, unsalted.Redact(l).Email)
+	assert.NotEqual(t, unsalted.Redact(l).Email, salted.Redact(l).Email)
+}
+
+func TestWriter_PublicRedaction(t *testing.T) {
+	writer := NewWriter(WithRedaction(PublicRedaction()))
+	buffer := &bytes.Buffer{}
+
+	err := writer.Write(buffer, redactionTestLog(), false)
+	require.NoError(t, err)
+
+	output := buffer.String()
+	assert.NotContains(t, output, "Meier")
+	assert.NotContains(t, output, "example.com")
+	assert.NotContains(t, output, "Berlin")
+	assert.NotContains(t, output, "10115")
+	assert.Contains(t, output, "ADDRESS-COUNTRY: Germany\n")
+	assert.Contains(t, output, "CLUB: Contest Club\n")
+	assert.Contains(t, output, "SOAPBOX: Great fun!\n")
+	assert.NotContains(t, output, "X-PHONE")
+	assert.NotContains(t, output, "X-NOTE")
+}
+
+func TestPublicRedaction_CustomAndExtensions(t *testing.T) {
+	l := redactionTestLog()
+	SetExtension(l, "X-QTH", "Berlin-Mitte")
+	SetExtension(l, "X-POWER", 100)
+	SetExtension(l, "X-RIG", "IC-7300")
+	redaction := PublicRedaction()
+	redaction.Policies["X-NOTE"] = KeepValue
+	redaction.Policies["X-QTH"] = MaskValue
+	redaction.Policies["X-POWER"] = KeepValue
+
+	actual := redaction.Redact(l)
+
+	assert.Equal(t, CustomTags{{"X-NOTE", "hello"}}, actual.Custom)
+	assert.Equal(t, Extensions{"X-QTH": "B*****-*****", "X-POWER": 100}, actual.Extensions)
+	assert.Len(t, l.Extensions, 3, "the original extensions must not be modified")
+}
@@ -0,0 +1,93 @@
+package cabrillo
+
+// ScoringRules describe a simple generic scoring scheme: every QSO that is not a dupe counts the same amount of points,
+// the multipliers are the distinct values of one element of the received exchange.
+type ScoringRules struct {
+	// PointsPerQSO is the number of points for each valid QSO.
+	PointsPerQSO int
+	// MultiplierElement is the 1-based position of the multiplier in the received exchange. 0 means no multipliers.
+	MultiplierElement int
+	// MultipliersPerBand counts the multipliers separately on each band.
+	MultipliersPerBand bool
+	// DupesPerMode allows to work the same station on the same band in different modes.
+	DupesPerMode bool
+}
+
+// BandModeScore is the part of the score that was achieved on one band in one mode.
+type BandModeScore struct {
+	Band        CategoryBand
+	Mode        QSOMode
+	QSOs        int
+	Dupes       int
+	Points      int
+	Multipliers int
+}
+
+// Score is the result of scoring a log.
+type Score struct {
+	Breakdown   []BandModeScore
+	QSOs        int
+	Dupes       int
+	Points      int
+	Multipliers int
+	Total       int
+}
+
+type multiplierKey struct {
+	band  CategoryBand
+	value string
+}
+
+// Score calculates the score for the given QSOs.
+func (r ScoringRules) Score(qsos []QSO) Score {
+	dupes := make(map[int]bool)
+	for _, dupe := range FindDupes(qsos, r.DupesPerMode) {
+		dupes[dupe.Index] = true
+	}
+
+	var result Score
+	breakdownIndex := make(map[BandModeScore]int)
+	multipliers := make(map[multiplierKey]bool)
+	for i, qso := range qsos {
+		band := qso.Frequency.ToBand()
+		key := BandModeScore{Band: band, Mode: qso.Mode}
+		index, found := breakdownIndex[key]
+		if !found {
+			index = len(result.Breakdown)
+			breakdownIndex[key] = index
+			result.Breakdown = append(result.Breakdown, key)
+		}
+		entry := &result.Breakdown[index]
+
+		if dupes[i] {
+			entry.Dupes++
+			result.Dupes++
+			continue
+		}
+		entry.QSOs++
+		entry.Points += r.PointsPerQSO
+		result.QSOs++
+		result.Points += r.PointsPerQSO
+
+		if r.MultiplierElement < 1 || r.MultiplierElement > len(qso.Received.Exchange) {
+			continue
+		}
+		multiplier := multiplierKey{value: qso.Received.Exchange[r.MultiplierElement-1]}
+		if r.MultipliersPerBand {
+			multiplier.band = band
+		}
+		if multipliers[multiplier] {
+			continue
+		}
+		multipliers[multiplier] = true
+		entry.Multipliers++
+		result.Multipliers++
+	}
+
+	if r.MultiplierElement > 0 {
+		result.Total = result.Points * result.Multipliers
+	} else {
+		result.Total = result.Points
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package cabrillo
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+)
+
+func scoringTestQSO(frequency QSOFrequency, mode QSOMode, call string, zone string) QSO {
+	return QSO{
+		Frequency: frequency,
+		Mode:      mode,
+		Sent:      QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}},
+		Received:  QSOInfo{Call: callsign.MustParse(call), Exchange: []string{"599", zone}},
+	}
+}
+
+func TestScoringRules_Score(t *testing.T) {
+	qsos := []QSO{
+		scoringTestQSO("14025", QSOModeCW, "W1AW", "5"),
+		scoringTestQSO("14026", QSOModeCW, "K1AR", "5"),
+		scoringTestQSO("7025", QSOModeCW, "W1AW", "5"),
+		scoringTestQSO("7026", QSOModeCW, "JA1ABC", "25"),
+		scoringTestQSO("7027", QSOModeCW, "JA1ABC", "25"),
+	}
+
+	tt := []struct {
+		desc     string
+		rules    ScoringRules
+		expected Score
+	}{
+		{
+			desc:  "no multipliers",
+			rules: ScoringRules{PointsPerQSO: 2},
+			expected: Score{
+				Breakdown: []BandModeScore{
+					{Band: Band20m, Mode: QSOModeCW, QSOs: 2, Points: 4},
+					{Band: Band40m, Mode: QSOModeCW, QSOs: 2, Dupes: 1, Points: 4},
+				},
+				QSOs: 4, Dupes: 1, Points: 8, Total: 8,
+			},
+		},
+		{
+			desc:  "multipliers once",
+			rules: ScoringRules{PointsPerQSO: 1, MultiplierElement: 2},
+			expected: Score{
+				Breakdown: []BandModeScore{
+					{Band: Band20m, Mode: QSOModeCW, QSOs: 2, Points: 2, Multipliers: 1},
+					{Band: Band40m, Mode: QSOModeCW, QSOs: 2, Dupes: 1, Points: 2, Multipliers: 1},
+				},
+				QSOs: 4, Dupes: 1, Points: 4, Multipliers: 2, Total: 8,
+			},
+		},
+		{
+			desc:  "multipliers per band",
+			rules: ScoringRules{PointsPerQSO: 1, MultiplierElement: 2, MultipliersPerBand: true},
+			expected: Score{
+				Breakdown: []BandModeScore{
+					{Band: Band20m, Mode: QSOModeCW, QSOs: 2, Points: 2, Multipliers: 1},
+					{Band: Band40m, Mode: QSOModeCW, QSOs: 2, Dupes: 1, Points: 2, Multipliers: 2},
+				},
+				QSOs: 4, Dupes: 1, Points: 4, Multipliers: 3, Total: 12,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.rules.Score(qsos))
+		})
+	}
+}
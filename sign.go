@@ -0,0 +1,235 @@
+package cabrillo
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ftl/hamradio/callsign"
+)
+
+// SignatureTag contains the base64 encoded ed25519 signature of the log. The signature is split into
+// several lines to keep the maximum line length.
+const SignatureTag Tag = "X-SIGNATURE"
+
+// signatureLineLength is the number of base64 characters in one line of the signature.
+const signatureLineLength = 60
+
+var (
+	// ErrNotSigned indicates that the log does not contain a signature.
+	ErrNotSigned = errors.New("the log is not signed")
+	// ErrInvalidSignature indicates that the log was modified after it was signed or that it was signed with another key.
+	ErrInvalidSignature = errors.New("the signature is invalid")
+)
+
+// Digest computes the SHA-256 digest of the given log without the signature tag. The digest covers all header
+// values, the custom tags, the values of tags that are registered on a Reader as they were read, and the QSO data.
+// Values that are only set with SetExtension are not covered.
+//
+// The digest is computed over a dedicated serialization that does not depend on the way a log is written,
+// so that reformatting the file does not change the digest, but any change of the data does. The serialization
+// (version 1) starts with the line "CABRILLO-DIGEST 1", followed by one line per value:
+//
+//	<TAG> "<value>" ...
+//
+// Each value is trimmed and quoted like strconv.Quote does. Tags, categories, the contest, callsigns, frequencies
+// and modes are uppercased. The header lines appear in the order of digestTags, followed by the custom values and
+// the values of the registered tags, sorted by tag with the values of each tag in their original order, the QSO
+// lines and the X-QSO lines. Multi-line values contribute one line per non-empty
+// line. The soapbox contributes one line without any whitespace, because long soapbox lines are wrapped when
+// the log is written. The exchanges of a QSO are preceded by their number of elements. Lines end with LF.
+func Digest(l *Log) ([]byte, error) {
+	digest := sha256.New()
+	err := writeDigestData(digest, l)
+	if err != nil {
+		return nil, err
+	}
+	return digest.Sum(nil), nil
+}
+
+// digestVersion is the version of the serialization that is used for the digest. It must be incremented with any
+// change of the serialization, otherwise existing signatures become invalid.
+const digestVersion = 1
+
+// digestTags is the order of the header tags in the digest serialization. It must never change.
+var digestTags = []Tag{
+	StartOfLogTag, CallsignTag, ContestTag, CategoryAssistedTag, CategoryBandTag, CategoryModeTag,
+	CategoryOperatorTag, CategoryPowerTag, CategoryStationTag, CategoryTimeTag, CategoryTransmitterTag,
+	CategoryOverlayTag, CertificateTag, ClaimedScoreTag, ClubTag, CreatedByTag, EmailTag, GridLocatorTag,
+	LocationTag, NameTag, AddressTag, AddressCityTag, AddressStateProvinceTag, AddressPostalcodeTag,
+	AddressCountryTag, OperatorsTag, OfftimeTag, SoapboxTag,
+}
+
+func writeDigestData(w io.Writer, l *Log) error {
+	upper := func(s string) string {
+		return strings.ToUpper(strings.TrimSpace(s))
+	}
+	lines := func(s string) []string {
+		var result []string
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				result = append(result, line)
+			}
+		}
+		return result
+	}
+	certificate := "NO"
+	if l.Certificate {
+		certificate = "YES"
+	}
+	var offtime string
+	if !l.Offtime.Begin.IsZero() && !l.Offtime.End.IsZero() {
+		offtime = formatTimestamp(l.Offtime.Begin) + " " + formatTimestamp(l.Offtime.End)
+	}
+	operators := make([]string, 0, len(l.Operators)+1)
+	if l.Host != callsign.NoCallsign {
+		operators = append(operators, "@"+formatCallsign(l.Host))
+	}
+	for _, operator := range l.Operators {
+		if operator != l.Host {
+			operators = append(operators, formatCallsign(operator))
+		}
+	}
+	header := map[Tag][]string{
+		StartOfLogTag:           {strings.TrimSpace(l.CabrilloVersion)},
+		CallsignTag:             {formatCallsign(l.Callsign)},
+		ContestTag:              {upper(string(l.Contest))},
+		CategoryAssistedTag:     {upper(string(l.Category.Assisted))},
+		CategoryBandTag:         {upper(string(l.Category.Band))},
+		CategoryModeTag:         {upper(string(l.Category.Mode))},
+		CategoryOperatorTag:     {upper(string(l.Category.Operator))},
+		CategoryPowerTag:        {upper(string(l.Category.Power))},
+		CategoryStationTag:      {upper(string(l.Category.Station))},
+		CategoryTimeTag:         {upper(string(l.Category.Time))},
+		CategoryTransmitterTag:  {upper(string(l.Category.Transmitter))},
+		CategoryOverlayTag:      {upper(string(l.Category.Overlay))},
+		CertificateTag:          {certificate},
+		ClaimedScoreTag:         {strconv.Itoa(l.ClaimedScore)},
+		ClubTag:                 {strings.TrimSpace(l.Club)},
+		CreatedByTag:            {strings.TrimSpace(l.CreatedBy)},
+		EmailTag:                {strings.TrimSpace(l.Email)},
+		GridLocatorTag:          {l.GridLocator.String()},
+		LocationTag:             {strings.TrimSpace(l.Location)},
+		NameTag:                 {strings.TrimSpace(l.Name)},
+		AddressTag:              lines(l.Address.Text),
+		AddressCityTag:          {strings.TrimSpace(l.Address.City)},
+		AddressStateProvinceTag: {strings.TrimSpace(l.Address.StateProvince)},
+		AddressPostalcodeTag:    {strings.TrimSpace(l.Address.Postalcode)},
+		AddressCountryTag:       {strings.TrimSpace(l.Address.Country)},
+		OperatorsTag:            operators,
+		OfftimeTag:              {offtime},
+		SoapboxTag:              {strings.Join(strings.Fields(l.Soapbox), "")},
+	}
+
+	_, err := fmt.Fprintf(w, "CABRILLO-DIGEST %d\n", digestVersion)
+	if err != nil {
+		return err
+	}
+	for _, tag := range digestTags {
+		err = writeDigestLine(w, tag, header[tag]...)
+		if err != nil {
+			return err
+		}
+	}
+	custom := make(CustomTags, 0, len(l.Custom)+len(l.extensionLines))
+	for _, value := range slices.Concat(l.Custom, l.extensionLines) {
+		tag := Tag(upper(string(value.Tag)))
+		if tag == SignatureTag {
+			continue
+		}
+		custom = append(custom, CustomValue{Tag: tag, Value: value.Value})
+	}
+	// the registered tags are stored apart from the custom tags, the order of the tags must not matter
+	slices.SortStableFunc(custom, func(a, b CustomValue) int {
+		return strings.Compare(string(a.Tag), string(b.Tag))
+	})
+	for _, value := range custom {
+		for _, line := range lines(value.Value) {
+			err = writeDigestLine(w, value.Tag, line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, data := range []struct {
+		tag  Tag
+		qsos []QSO
+	}{{QSOTag, l.QSOData}, {XQSOTag, l.IgnoredQSOs}} {
+		for _, qso := range data.qsos {
+			values := []string{
+				upper(string(qso.Frequency)),
+				upper(string(qso.Mode)),
+				formatTimestamp(qso.Timestamp),
+				formatCallsign(qso.Sent.Call),
+				strconv.Itoa(len(qso.Sent.Exchange)),
+			}
+			values = append(values, qso.Sent.Exchange...)
+			values = append(values, formatCallsign(qso.Received.Call), strconv.Itoa(len(qso.Received.Exchange)))
+			values = append(values, qso.Received.Exchange...)
+			values = append(values, strconv.Itoa(qso.Transmitter))
+			err = writeDigestLine(w, data.tag, values...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeDigestLine(w io.Writer, tag Tag, values ...string) error {
+	var b strings.Builder
+	b.WriteString(string(tag))
+	for _, value := range values {
+		b.WriteString(" ")
+		b.WriteString(strconv.Quote(strings.TrimSpace(value)))
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Sign signs the digest of the given log with the given key and stores the signature in the log.
+// An existing signature is replaced.
+func Sign(l *Log, key ed25519.PrivateKey) error {
+	digest, err := Digest(l)
+	if err != nil {
+		return err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest))
+
+	lines := make([]string, 0, len(signature)/signatureLineLength+1)
+	for len(signature) > signatureLineLength {
+		lines = append(lines, signature[:signatureLineLength])
+		signature = signature[signatureLineLength:]
+	}
+	lines = append(lines, signature)
+	l.Custom.Set(SignatureTag, lines...)
+	return nil
+}
+
+// Verify checks the signature of the given log with the given public key. It returns ErrNotSigned if the log
+// contains no signature and ErrInvalidSignature if the log was modified after signing.
+func Verify(l *Log, key ed25519.PublicKey) error {
+	lines := l.Custom.Values(SignatureTag)
+	if len(lines) == 0 {
+		return ErrNotSigned
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	digest, err := Digest(l)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, digest, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
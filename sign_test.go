@@ -0,0 +1,199 @@
+package cabrillo
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readSignTestLog(t *testing.T) *Log {
+	t.Helper()
+	f, err := os.Open("testdata/cqww.v3.cabrillo")
+	require.NoError(t, err)
+	defer f.Close()
+	l, err := Read(f)
+	require.NoError(t, err)
+	return l
+}
+
+func TestSignAndVerify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	tt := []struct {
+		desc     string
+		modify   func(*Log)
+		key      ed25519.PublicKey
+		expected error
+	}{
+		{desc: "unmodified", modify: func(*Log) {}, key: publicKey},
+		{desc: "reformatted values", modify: func(l *Log) { l.Name = " " + l.Name + " "; l.Category.Mode = "ph" }, key: publicKey},
+		{desc: "other key", modify: func(*Log) {}, key: otherKey, expected: ErrInvalidSignature},
+		{desc: "modified header", modify: func(l *Log) { l.ClaimedScore++ }, key: publicKey, expected: ErrInvalidSignature},
+		{desc: "modified QSO", modify: func(l *Log) { l.QSOData[2].Received.Call = callsign.MustParse("DL1ABC") }, key: publicKey, expected: ErrInvalidSignature},
+		{desc: "removed QSO", modify: func(l *Log) { l.QSOData = l.QSOData[1:] }, key: publicKey, expected: ErrInvalidSignature},
+		{desc: "modified custom tag", modify: func(l *Log) { l.Custom.Add("X-NOTE", "added") }, key: publicKey, expected: ErrInvalidSignature},
+		{desc: "removed signature", modify: func(l *Log) { l.Custom.Delete(SignatureTag) }, key: publicKey, expected: ErrNotSigned},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			l := readSignTestLog(t)
+			err := Sign(l, privateKey)
+			require.NoError(t, err)
+
+			tc.modify(l)
+			err = Verify(l, tc.key)
+
+			if tc.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSign_SurvivesRoundtrip(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	l := readSignTestLog(t)
+	err = Sign(l, privateKey)
+	require.NoError(t, err)
+
+	buffer := &bytes.Buffer{}
+	err = Write(buffer, l, true)
+	require.NoError(t, err)
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength)
+	}
+	reread, err := Read(strings.NewReader(strings.ReplaceAll(buffer.String(), ": ", ":   ")))
+	require.NoError(t, err)
+
+	assert.NoError(t, Verify(reread, publicKey))
+}
+
+func TestSign_ReplacesSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	l := readSignTestLog(t)
+
+	require.NoError(t, Sign(l, privateKey))
+	require.NoError(t, Sign(l, privateKey))
+
+	assert.Len(t, l.Custom.Values(SignatureTag), 2)
+	assert.NoError(t, Verify(l, publicKey))
+}
+
+func TestDigest_Serialization(t *testing.T) {
+	l := NewLog()
+	l.CabrilloVersion = "3.0"
+	l.Callsign = callsign.MustParse("DL1ABC")
+	l.Contest = "cq-ww-cw"
+	l.Operators = []callsign.Callsign{callsign.MustParse("DL2ABC"), callsign.MustParse("DL1ABC")}
+	l.Host = callsign.MustParse("DL1ABC")
+	l.Address.Text = "Hauptstr. 1\n\nc/o Meier"
+	l.Soapbox = "Great\nfun!"
+	l.Custom = CustomTags{{"X-NOTE", "a \"quoted\" note"}, {SignatureTag, "ignored"}}
+	l.extensionLines = CustomTags{{"X-POWER", "100"}, {"X-ANTENNA", "dipole"}}
+	l.QSOData = []QSO{{
+		Frequency: "14025",
+		Mode:      QSOModeCW,
+		Timestamp: time.Date(2024, time.October, 26, 7, 11, 0, 0, time.UTC),
+		Sent:      QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}},
+		Received:  QSOInfo{Call: callsign.MustParse("W1AW"), Exchange: []string{"599", "5"}},
+	}}
+	expected := `CABRILLO-DIGEST 1
+START-OF-LOG "3.0"
+CALLSIGN "DL1ABC"
+CONTEST "CQ-WW-CW"
+CATEGORY-ASSISTED ""
+CATEGORY-BAND ""
+CATEGORY-MODE ""
+CATEGORY-OPERATOR ""
+CATEGORY-POWER ""
+CATEGORY-STATION ""
+CATEGORY-TIME ""
+CATEGORY-TRANSMITTER ""
+CATEGORY-OVERLAY ""
+CERTIFICATE "NO"
+CLAIMED-SCORE "0"
+CLUB ""
+CREATED-BY ""
+EMAIL ""
+GRID-LOCATOR ""
+LOCATION ""
+NAME ""
+ADDRESS "Hauptstr. 1" "c/o Meier"
+ADDRESS-CITY ""
+ADDRESS-STATE-PROVINCE ""
+ADDRESS-POSTALCODE ""
+ADDRESS-COUNTRY ""
+OPERATORS "@DL1ABC" "DL2ABC"
+OFFTIME ""
+SOAPBOX "Greatfun!"
+X-ANTENNA "dipole"
+X-NOTE "a \"quoted\" note"
+X-POWER "100"
+QSO "14025" "CW" "2024-10-26 0711" "DL1ABC" "2" "599" "14" "W1AW" "2" "599" "5" "0"
+`
+	buffer := &bytes.Buffer{}
+
+	err := writeDigestData(buffer, l)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestDigest_IndependentOfTheWriter(t *testing.T) {
+	l := readSignTestLog(t)
+	l.Soapbox = strings.Repeat("a long soapbox line that is wrapped when the log is written ", 3)
+	digest, err := Digest(l)
+	require.NoError(t, err)
+
+	buffer := &bytes.Buffer{}
+	err = NewWriter(WithAlignedQSOs(true), WithTransmitterColumn(TransmitterAlways), WithLineEnding(CRLF)).WriteLog(buffer, l)
+	require.NoError(t, err)
+	reread, err := Read(buffer)
+	require.NoError(t, err)
+	rereadDigest, err := Digest(reread)
+	require.NoError(t, err)
+
+	assert.Equal(t, digest, rereadDigest)
+}
+
+func TestSign_RegisteredTags(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	l := readSignTestLog(t)
+	l.Custom.Add("X-POWER", "100")
+	l.Custom.Add("X-NOTE", "registered tags are signed")
+	require.NoError(t, Sign(l, privateKey))
+	buffer := &bytes.Buffer{}
+	require.NoError(t, Write(buffer, l, false))
+	reader := NewReader()
+	reader.RegisterTag("X-POWER", func(l *Log, value string) error {
+		power, err := strconv.Atoi(value)
+		SetExtension(l, "X-POWER", power)
+		return err
+	})
+
+	reread, err := reader.Read(strings.NewReader(buffer.String()))
+	require.NoError(t, err)
+	assert.NoError(t, Verify(reread, publicKey))
+
+	modified, err := reader.Read(strings.NewReader(strings.ReplaceAll(buffer.String(), "X-POWER: 100", "X-POWER: 1500")))
+	require.NoError(t, err)
+	power, _ := Extension[int](modified, "X-POWER")
+	require.Equal(t, 1500, power)
+	assert.ErrorIs(t, Verify(modified, publicKey), ErrInvalidSignature)
+}
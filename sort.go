@@ -0,0 +1,63 @@
+package cabrillo
+
+import (
+	"cmp"
+	"slices"
+)
+
+// CompareQSOs compares two QSOs by timestamp, then by transmitter, then by frequency. It returns -1 if a comes
+// before b, 1 if a comes after b and 0 if the order of a and b is undefined.
+func CompareQSOs(a, b QSO) int {
+	return cmp.Or(
+		a.Timestamp.Compare(b.Timestamp),
+		cmp.Compare(a.Transmitter, b.Transmitter),
+		compareFrequencies(a.Frequency, b.Frequency),
+	)
+}
+
+func compareFrequencies(a, b QSOFrequency) int {
+	if a.IsFrequency() && b.IsFrequency() {
+		return cmp.Compare(a.ToKilohertz(), b.ToKilohertz())
+	}
+	return cmp.Compare(a, b)
+}
+
+// SortQSOs sorts the given QSOs in place using CompareQSOs. QSOs that compare as equal keep their original order.
+func SortQSOs(qsos []QSO) {
+	slices.SortStableFunc(qsos, CompareQSOs)
+}
+
+// SortLog sorts the QSO data and the ignored QSOs of the given log in place.
+func SortLog(l *Log) {
+	SortQSOs(l.QSOData)
+	SortQSOs(l.IgnoredQSOs)
+}
+
+// sortedQSOs returns a sorted copy of the given QSOs.
+func sortedQSOs(qsos []QSO) []QSO {
+	result := slices.Clone(qsos)
+	SortQSOs(result)
+	return result
+}
+
+// OutOfOrderQSO describes a QSO that comes before a former QSO in chronological order.
+// Index and Predecessor refer to the position of the QSOs in the given slice, Predecessor is
+// the position of the former QSO with the latest timestamp.
+type OutOfOrderQSO struct {
+	Index       int
+	Predecessor int
+}
+
+// FindOutOfOrderQSOs returns all QSOs that have an earlier timestamp than any former QSO.
+func FindOutOfOrderQSOs(qsos []QSO) []OutOfOrderQSO {
+	var result []OutOfOrderQSO
+	latest := -1
+	for i, qso := range qsos {
+		if latest != -1 && qso.Timestamp.Before(qsos[latest].Timestamp) {
+			result = append(result, OutOfOrderQSO{Index: i, Predecessor: latest})
+			continue
+		}
+		latest = i
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package cabrillo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sortTestQSO(minute int, frequency QSOFrequency, transmitter int, call string) QSO {
+	qso := scoringTestQSO(frequency, QSOModeCW, call, "5")
+	qso.Timestamp = time.Date(2024, time.October, 26, 0, minute, 0, 0, time.UTC)
+	qso.Transmitter = transmitter
+	return qso
+}
+
+func TestSortQSOs(t *testing.T) {
+	qsos := []QSO{
+		sortTestQSO(5, "14025", 0, "W1AW"),
+		sortTestQSO(1, "7025", 1, "K1AR"),
+		sortTestQSO(1, "14025", 0, "JA1ABC"),
+		sortTestQSO(1, "7025", 0, "DL2XYZ"),
+		sortTestQSO(1, "7025", 0, "DL3XYZ"),
+		sortTestQSO(0, "50", 0, "OH2XX"),
+	}
+
+	SortQSOs(qsos)
+
+	calls := make([]string, len(qsos))
+	for i, qso := range qsos {
+		calls[i] = qso.Received.Call.String()
+	}
+	assert.Equal(t, []string{"OH2XX", "DL2XYZ", "DL3XYZ", "JA1ABC", "K1AR", "W1AW"}, calls)
+}
+
+func TestFindOutOfOrderQSOs(t *testing.T) {
+	tt := []struct {
+		desc     string
+		minutes  []int
+		expected []OutOfOrderQSO
+	}{
+		{desc: "empty", minutes: nil, expected: nil},
+		{desc: "in order", minutes: []int{0, 1, 1, 2}, expected: nil},
+		{desc: "single QSO out of order", minutes: []int{0, 2, 1, 3}, expected: []OutOfOrderQSO{{Index: 2, Predecessor: 1}}},
+		{desc: "late QSO", minutes: []int{0, 9, 1, 2, 10}, expected: []OutOfOrderQSO{{Index: 2, Predecessor: 1}, {Index: 3, Predecessor: 1}}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			qsos := make([]QSO, len(tc.minutes))
+			for i, minute := range tc.minutes {
+				qsos[i] = sortTestQSO(minute, "14025", 0, "W1AW")
+			}
+
+			assert.Equal(t, tc.expected, FindOutOfOrderQSOs(qsos))
+		})
+	}
+}
+
+func TestWriter_SortQSOs(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.QSOData = []QSO{
+		sortTestQSO(2, "14025", 0, "W1AW"),
+		sortTestQSO(1, "14025", 0, "K1AR"),
+	}
+	writer := NewWriter(WithSortedQSOs(true))
+	buffer := &bytes.Buffer{}
+
+	err := writer.WriteWithTags(buffer, log, false, true)
+	require.NoError(t, err)
+
+	lines := strings.Split(buffer.String(), "\n")
+	assert.Contains(t, lines[1], "K1AR")
+	assert.Contains(t, lines[2], "W1AW")
+	assert.Equal(t, "K1AR", log.QSOData[1].Received.Call.String(), "the log must not be modified")
+}
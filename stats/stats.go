@@ -0,0 +1,227 @@
+// Package stats computes statistics about the QSO data of a Cabrillo log.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+
+	"github.com/ftl/cabrillo"
+)
+
+// ContinentFinder finds the continent of a callsign, e.g. using a prefix database.
+type ContinentFinder interface {
+	FindContinent(call callsign.Callsign) (string, bool)
+}
+
+// ContinentFinderFunc is a function that implements ContinentFinder.
+type ContinentFinderFunc func(call callsign.Callsign) (string, bool)
+
+func (f ContinentFinderFunc) FindContinent(call callsign.Callsign) (string, bool) {
+	return f(call)
+}
+
+// Count is the number of QSOs for a particular key, e.g. a band or a mode.
+type Count struct {
+	Key  string `json:"key"`
+	QSOs int    `json:"qsos"`
+}
+
+// Rate is the number of QSOs within a period of time.
+type Rate struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	QSOs  int       `json:"qsos"`
+}
+
+// PerHour converts the rate into QSOs per hour.
+func (r Rate) PerHour() float64 {
+	duration := r.End.Sub(r.Start)
+	if duration <= 0 {
+		return 0
+	}
+	return float64(r.QSOs) * float64(time.Hour) / float64(duration)
+}
+
+// Stats contains the statistics of a log.
+type Stats struct {
+	QSOs        int       `json:"qsos"`
+	IgnoredQSOs int       `json:"ignored_qsos"`
+	UniqueCalls int       `json:"unique_calls"`
+	FirstQSO    time.Time `json:"first_qso"`
+	LastQSO     time.Time `json:"last_qso"`
+
+	Bands        []Count `json:"bands"`
+	Modes        []Count `json:"modes"`
+	Hours        []Count `json:"hours"`
+	Transmitters []Count `json:"transmitters"`
+	// Continents is only filled if a ContinentFinder is given.
+	Continents []Count `json:"continents,omitempty"`
+
+	Best10Minutes Rate `json:"best_10_minutes"`
+	Best60Minutes Rate `json:"best_60_minutes"`
+}
+
+const hourLayout = "2006-01-02 15"
+
+// Compute calculates the statistics from the QSO data of the given log. The continent finder is optional and may be nil.
+func Compute(l *cabrillo.Log, continents ContinentFinder) Stats {
+	qsos := l.QSOData
+	result := Stats{QSOs: len(qsos), IgnoredQSOs: len(l.IgnoredQSOs)}
+
+	bands := newCounter()
+	modes := newCounter()
+	hours := newCounter()
+	transmitters := newCounter()
+	continentCounts := newCounter()
+	uniqueCalls := make(map[string]bool)
+	timestamps := make([]time.Time, 0, len(qsos))
+	for _, qso := range qsos {
+		bands.add(string(qso.Frequency.ToBand()))
+		modes.add(string(qso.Mode))
+		hours.add(qso.Timestamp.UTC().Format(hourLayout))
+		transmitters.add(strconv.Itoa(qso.Transmitter))
+		if continents != nil {
+			continent, ok := continents.FindContinent(qso.Received.Call)
+			if !ok {
+				continent = "unknown"
+			}
+			continentCounts.add(continent)
+		}
+		uniqueCalls[qso.Received.Call.String()] = true
+		timestamps = append(timestamps, qso.Timestamp)
+	}
+	result.UniqueCalls = len(uniqueCalls)
+	result.Bands = bands.counts()
+	result.Modes = modes.counts()
+	result.Hours = hours.sortedCounts()
+	result.Transmitters = transmitters.sortedCounts()
+	if continents != nil {
+		result.Continents = continentCounts.counts()
+	}
+
+	slices.SortFunc(timestamps, func(a, b time.Time) int { return a.Compare(b) })
+	if len(timestamps) > 0 {
+		result.FirstQSO = timestamps[0]
+		result.LastQSO = timestamps[len(timestamps)-1]
+	}
+	result.Best10Minutes = bestRate(timestamps, 10*time.Minute)
+	result.Best60Minutes = bestRate(timestamps, 60*time.Minute)
+
+	return result
+}
+
+// bestRate finds the period of the given length with the most QSOs. The timestamps must be sorted.
+func bestRate(timestamps []time.Time, length time.Duration) Rate {
+	var result Rate
+	end := 0
+	for start := range timestamps {
+		for end < len(timestamps) && timestamps[end].Before(timestamps[start].Add(length)) {
+			end++
+		}
+		count := end - start
+		if count > result.QSOs {
+			result = Rate{Start: timestamps[start], End: timestamps[start].Add(length), QSOs: count}
+		}
+	}
+	return result
+}
+
+type counter struct {
+	keys   []string
+	values map[string]int
+}
+
+func newCounter() *counter {
+	return &counter{values: make(map[string]int)}
+}
+
+func (c *counter) add(key string) {
+	if _, found := c.values[key]; !found {
+		c.keys = append(c.keys, key)
+	}
+	c.values[key]++
+}
+
+// counts returns the counts in the order of the first occurrence of each key.
+func (c *counter) counts() []Count {
+	result := make([]Count, len(c.keys))
+	for i, key := range c.keys {
+		result[i] = Count{Key: key, QSOs: c.values[key]}
+	}
+	return result
+}
+
+// sortedCounts returns the counts ordered by key.
+func (c *counter) sortedCounts() []Count {
+	result := c.counts()
+	slices.SortFunc(result, func(a, b Count) int {
+		aNumber, aErr := strconv.Atoi(a.Key)
+		bNumber, bErr := strconv.Atoi(b.Key)
+		if aErr == nil && bErr == nil {
+			return aNumber - bNumber
+		}
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	})
+	return result
+}
+
+// WriteJSON writes the statistics as JSON.
+func (s Stats) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteText writes the statistics as human-readable text.
+func (s Stats) WriteText(w io.Writer) error {
+	tw := &textWriter{w: w}
+	tw.printf("QSOs:           %d\n", s.QSOs)
+	tw.printf("Ignored QSOs:   %d\n", s.IgnoredQSOs)
+	tw.printf("Unique calls:   %d\n", s.UniqueCalls)
+	if s.QSOs > 0 {
+		tw.printf("First QSO:      %s\n", s.FirstQSO.UTC().Format(cabrillo.TimestampLayout))
+		tw.printf("Last QSO:       %s\n", s.LastQSO.UTC().Format(cabrillo.TimestampLayout))
+		tw.printf("Best 10 min:    %d QSOs (%.0f/h) from %s\n", s.Best10Minutes.QSOs, s.Best10Minutes.PerHour(), s.Best10Minutes.Start.UTC().Format(cabrillo.TimestampLayout))
+		tw.printf("Best 60 min:    %d QSOs (%.0f/h) from %s\n", s.Best60Minutes.QSOs, s.Best60Minutes.PerHour(), s.Best60Minutes.Start.UTC().Format(cabrillo.TimestampLayout))
+	}
+	tw.counts("Bands", s.Bands)
+	tw.counts("Modes", s.Modes)
+	tw.counts("Transmitters", s.Transmitters)
+	if len(s.Continents) > 0 {
+		tw.counts("Continents", s.Continents)
+	}
+	tw.counts("Hours", s.Hours)
+	return tw.err
+}
+
+// textWriter keeps the first error, so the text can be written without checking every single line.
+type textWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *textWriter) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+func (w *textWriter) counts(title string, counts []Count) {
+	w.printf("%s:\n", title)
+	for _, count := range counts {
+		w.printf("  %-14s %6d\n", count.Key, count.QSOs)
+	}
+}
@@ -0,0 +1,138 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/cabrillo"
+)
+
+var startTime = time.Date(2024, time.October, 26, 0, 0, 0, 0, time.UTC)
+
+func testQSO(minute int, frequency cabrillo.QSOFrequency, mode cabrillo.QSOMode, call string, transmitter int) cabrillo.QSO {
+	return cabrillo.QSO{
+		Frequency:   frequency,
+		Mode:        mode,
+		Timestamp:   startTime.Add(time.Duration(minute) * time.Minute),
+		Sent:        cabrillo.QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}},
+		Received:    cabrillo.QSOInfo{Call: callsign.MustParse(call), Exchange: []string{"599", "5"}},
+		Transmitter: transmitter,
+	}
+}
+
+func testLog() *cabrillo.Log {
+	l := cabrillo.NewLog()
+	l.QSOData = []cabrillo.QSO{
+		testQSO(0, "14025", cabrillo.QSOModeCW, "W1AW", 0),
+		testQSO(3, "14026", cabrillo.QSOModeCW, "K1AR", 0),
+		testQSO(5, "7025", cabrillo.QSOModeCW, "W1AW", 1),
+		testQSO(9, "7026", cabrillo.QSOModeCW, "JA1ABC", 1),
+		testQSO(30, "14250", cabrillo.QSOModePhone, "DL2XYZ", 0),
+		testQSO(75, "14251", cabrillo.QSOModePhone, "W1AW", 0),
+	}
+	return l
+}
+
+func TestCompute(t *testing.T) {
+	continents := ContinentFinderFunc(func(call callsign.Callsign) (string, bool) {
+		switch call.BaseCall[0] {
+		case 'W', 'K':
+			return "NA", true
+		case 'J':
+			return "AS", true
+		default:
+			return "", false
+		}
+	})
+
+	actual := Compute(testLog(), continents)
+
+	assert.Equal(t, 6, actual.QSOs)
+	assert.Equal(t, 4, actual.UniqueCalls)
+	assert.Equal(t, startTime, actual.FirstQSO)
+	assert.Equal(t, startTime.Add(75*time.Minute), actual.LastQSO)
+	assert.Equal(t, []Count{{Key: "20M", QSOs: 4}, {Key: "40M", QSOs: 2}}, actual.Bands)
+	assert.Equal(t, []Count{{Key: "CW", QSOs: 4}, {Key: "PH", QSOs: 2}}, actual.Modes)
+	assert.Equal(t, []Count{{Key: "2024-10-26 00", QSOs: 5}, {Key: "2024-10-26 01", QSOs: 1}}, actual.Hours)
+	assert.Equal(t, []Count{{Key: "0", QSOs: 4}, {Key: "1", QSOs: 2}}, actual.Transmitters)
+	assert.Equal(t, []Count{{Key: "NA", QSOs: 4}, {Key: "AS", QSOs: 1}, {Key: "unknown", QSOs: 1}}, actual.Continents)
+	assert.Equal(t, Rate{Start: startTime, End: startTime.Add(10 * time.Minute), QSOs: 4}, actual.Best10Minutes)
+	assert.Equal(t, Rate{Start: startTime, End: startTime.Add(60 * time.Minute), QSOs: 5}, actual.Best60Minutes)
+	assert.Equal(t, 24.0, actual.Best10Minutes.PerHour())
+}
+
+func TestCompute_WithoutContinents(t *testing.T) {
+	actual := Compute(testLog(), nil)
+
+	assert.Nil(t, actual.Continents)
+}
+
+func TestCompute_Empty(t *testing.T) {
+	actual := Compute(cabrillo.NewLog(), nil)
+
+	assert.Equal(t, 0, actual.QSOs)
+	assert.True(t, actual.FirstQSO.IsZero())
+	assert.Equal(t, Rate{}, actual.Best10Minutes)
+}
+
+func TestBestRate(t *testing.T) {
+	tt := []struct {
+		desc     string
+		minutes  []int
+		expected int
+		start    int
+	}{
+		{desc: "empty", minutes: nil, expected: 0},
+		{desc: "single QSO", minutes: []int{5}, expected: 1, start: 5},
+		{desc: "end is exclusive", minutes: []int{0, 10}, expected: 1, start: 0},
+		{desc: "later window", minutes: []int{0, 20, 21, 22, 35}, expected: 3, start: 20},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			timestamps := make([]time.Time, len(tc.minutes))
+			for i, minute := range tc.minutes {
+				timestamps[i] = startTime.Add(time.Duration(minute) * time.Minute)
+			}
+
+			actual := bestRate(timestamps, 10*time.Minute)
+
+			assert.Equal(t, tc.expected, actual.QSOs)
+			if tc.expected > 0 {
+				assert.Equal(t, startTime.Add(time.Duration(tc.start)*time.Minute), actual.Start)
+			}
+		})
+	}
+}
+
+func TestStats_WriteText(t *testing.T) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := Compute(testLog(), nil).WriteText(buffer)
+	require.NoError(t, err)
+
+	text := buffer.String()
+	assert.True(t, strings.HasPrefix(text, "QSOs:           6\n"), text)
+	assert.Contains(t, text, "Best 10 min:    4 QSOs (24/h) from 2024-10-26 0000\n")
+	assert.Contains(t, text, "Bands:\n  20M                 4\n  40M                 2\n")
+	assert.NotContains(t, text, "Continents:")
+}
+
+func TestStats_WriteJSON(t *testing.T) {
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := Compute(testLog(), nil).WriteJSON(buffer)
+	require.NoError(t, err)
+
+	var actual map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &actual))
+	assert.Equal(t, 6.0, actual["qsos"])
+	assert.Equal(t, 4.0, actual["best_10_minutes"].(map[string]any)["qsos"])
+	assert.NotContains(t, actual, "continents")
+}
@@ -0,0 +1,82 @@
+package cabrillo
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WriteSummary writes a summary of the given log and score in the style of the score postings on 3830scores.com:
+// the header fields, a band/mode breakdown with QSOs and multipliers, the total score and the soapbox.
+func WriteSummary(w io.Writer, l *Log, score Score) error {
+	var b strings.Builder
+
+	if l.Contest != "" {
+		fmt.Fprintf(&b, "%s\n\n", l.Contest)
+	}
+	fmt.Fprintf(&b, "Call: %s\n", formatCallsign(l.Callsign))
+	if listed := listedOperators(l); len(listed) > 0 {
+		operators := make([]string, len(listed))
+		for i, operator := range listed {
+			operators[i] = formatCallsign(operator)
+		}
+		fmt.Fprintf(&b, "Operator(s): %s\n", strings.Join(operators, " "))
+	}
+	if l.Host.String() != "" {
+		fmt.Fprintf(&b, "Station: %s\n", formatCallsign(l.Host))
+	}
+	b.WriteString("\n")
+
+	category := l.Category.String()
+	if category != "" {
+		fmt.Fprintf(&b, "Class: %s\n", category)
+	}
+	if l.Location != "" {
+		fmt.Fprintf(&b, "QTH: %s\n", l.Location)
+	}
+	if category != "" || l.Location != "" {
+		b.WriteString("\n")
+	}
+
+	const separator = "---------------------------\n"
+	b.WriteString("Summary:\n")
+	fmt.Fprintf(&b, "%6s %6s %6s %6s\n", "Band", "Mode", "QSOs", "Mults")
+	b.WriteString(separator)
+	for _, entry := range sortedBreakdown(score.Breakdown) {
+		fmt.Fprintf(&b, "%6s %6s %6d %6d\n", entry.Band, entry.Mode, entry.QSOs, entry.Multipliers)
+	}
+	b.WriteString(separator)
+	fmt.Fprintf(&b, "%6s %6s %6d %6d\n", "Total", "", score.QSOs, score.Multipliers)
+	fmt.Fprintf(&b, "Total Score = %d\n", score.Total)
+
+	if l.Club != "" {
+		fmt.Fprintf(&b, "\nClub: %s\n", l.Club)
+	}
+	if soapbox := strings.TrimSpace(l.Soapbox); soapbox != "" {
+		fmt.Fprintf(&b, "\nComments:\n%s\n", soapbox)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sortedBreakdown returns a copy of the breakdown, ordered from the lowest to the highest band and by mode.
+func sortedBreakdown(breakdown []BandModeScore) []BandModeScore {
+	result := slices.Clone(breakdown)
+	bandIndex := func(band CategoryBand) int {
+		index := slices.Index(categoryBandValues, band)
+		if index == -1 {
+			return len(categoryBandValues)
+		}
+		return index
+	}
+	slices.SortStableFunc(result, func(a, b BandModeScore) int {
+		return cmp.Or(
+			cmp.Compare(bandIndex(a.Band), bandIndex(b.Band)),
+			cmp.Compare(a.Mode, b.Mode),
+		)
+	})
+	return result
+}
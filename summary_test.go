@@ -0,0 +1,79 @@
+package cabrillo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSummary(t *testing.T) {
+	l := NewLog()
+	l.Contest = "CQ-WW-CW"
+	l.Callsign = callsign.MustParse("DL0ABC")
+	l.Operators = []callsign.Callsign{callsign.MustParse("DL1ABC"), callsign.MustParse("DL2XYZ"), callsign.MustParse("DL0ABC")}
+	l.Host = callsign.MustParse("DL0ABC")
+	l.Category = Category{Operator: MultiOperator, Band: BandAll, Power: HighPower, Transmitter: OneTransmitter}
+	l.Club = "Contest Club"
+	l.Soapbox = "Great conditions.\nSee you next year!"
+	score := Score{
+		Breakdown: []BandModeScore{
+			{Band: Band20m, Mode: QSOModeCW, QSOs: 120, Multipliers: 30},
+			{Band: Band80m, Mode: QSOModeCW, QSOs: 40, Multipliers: 12},
+		},
+		QSOs:        160,
+		Multipliers: 42,
+		Total:       13440,
+	}
+	expected := `CQ-WW-CW
+
+Call: DL0ABC
+Operator(s): DL1ABC DL2XYZ
+Station: DL0ABC
+
+Class: MULTI-OP ALL HIGH ONE
+
+Summary:
+  Band   Mode   QSOs  Mults
+---------------------------
+   80M     CW     40     12
+   20M     CW    120     30
+---------------------------
+ Total           160     42
+Total Score = 13440
+
+Club: Contest Club
+
+Comments:
+Great conditions.
+See you next year!
+`
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := WriteSummary(buffer, l, score)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestWriteSummary_MinimalHeader(t *testing.T) {
+	l := NewLog()
+	l.Callsign = callsign.MustParse("dl1abc")
+	expected := `Call: DL1ABC
+
+Summary:
+  Band   Mode   QSOs  Mults
+---------------------------
+---------------------------
+ Total             0      0
+Total Score = 0
+`
+	buffer := bytes.NewBuffer([]byte{})
+
+	err := WriteSummary(buffer, l, Score{})
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, buffer.String())
+}
@@ -0,0 +1,134 @@
+package cabrillo
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ftl/hamradio/callsign"
+)
+
+// Problem describes an issue that was found in a log. QSO is the index of the related QSO
+// in QSOData or -1 if the problem is not related to a particular QSO.
+type Problem struct {
+	Tag     Tag
+	QSO     int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.QSO >= 0 {
+		return fmt.Sprintf("%s %d: %s", p.Tag, p.QSO+1, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Tag, p.Message)
+}
+
+func headerProblem(tag Tag, format string, args ...any) Problem {
+	return Problem{Tag: tag, QSO: -1, Message: fmt.Sprintf(format, args...)}
+}
+
+func qsoProblem(index int, format string, args ...any) Problem {
+	return Problem{Tag: QSOTag, QSO: index, Message: fmt.Sprintf(format, args...)}
+}
+
+var (
+	categoryAssistedValues    = []CategoryAssisted{Assisted, NonAssisted}
+	categoryBandValues        = []CategoryBand{BandAll, Band160m, Band80m, Band40m, Band20m, Band15m, Band10m, Band6m, Band4m, Band2m, Band222, Band432, Band902, Band1_2G, Band2_3G, Band3_4G, Band5_6G, Band10G, Band24G, Band47G, Band75G, Band122G, Band134G, Band241G, BandLight, BandVHF_3Band, BandVHF_FMOnly}
+	categoryModeValues        = []CategoryMode{ModeCW, ModeDIGI, ModeFM, ModeRTTY, ModeSSB, ModeMIXED}
+	categoryOperatorValues    = []CategoryOperator{SingleOperator, MultiOperator, Checklog}
+	categoryPowerValues       = []CategoryPower{HighPower, LowPower, QRP}
+	categoryStationValues     = []CategoryStation{DistributedStation, FixedStation, MobileStation, PortableStation, RoverStation, RoverLimitedStation, RoverUnlimitedStation, ExpeditionStation, HQStation, SchoolStation, ExplorerStation}
+	categoryTimeValues        = []CategoryTime{Hours6, Hours8, Hours12, Hours24}
+	categoryTransmitterValues = []CategoryTransmitter{OneTransmitter, TwoTransmitter, LimitedTransmitter, UnlimitedTransmitter, SWL}
+	categoryOverlayValues     = []CategoryOverlay{ClassicOverlay, RookieOverlay, TBWiresOverlay, YouthOverlay, NoviceTechOverlay, Over50Overlay, YLOverlay}
+	qsoModeValues             = []QSOMode{QSOModeCW, QSOModePhone, QSOModeFM, QSOModeRTTY, QSOModeDigi}
+)
+
+// Validate checks the given log against the rules of the Cabrillo specification
+// and returns all problems that were found. The result is empty if the log is valid.
+func Validate(l *Log) []Problem {
+	var result []Problem
+
+	if l.CabrilloVersion != Version2 && l.CabrilloVersion != Version3 {
+		result = append(result, headerProblem(StartOfLogTag, "unsupported Cabrillo version %q", l.CabrilloVersion))
+	}
+	if l.Callsign.String() == "" {
+		result = append(result, headerProblem(CallsignTag, "the callsign is missing"))
+	}
+	if l.Contest == "" {
+		result = append(result, headerProblem(ContestTag, "the contest identifier is missing"))
+	}
+
+	result = appendCategoryProblem(result, CategoryAssistedTag, l.Category.Assisted, categoryAssistedValues)
+	result = appendCategoryProblem(result, CategoryBandTag, l.Category.Band, categoryBandValues)
+	result = appendCategoryProblem(result, CategoryModeTag, l.Category.Mode, categoryModeValues)
+	result = appendCategoryProblem(result, CategoryOperatorTag, l.Category.Operator, categoryOperatorValues)
+	result = appendCategoryProblem(result, CategoryPowerTag, l.Category.Power, categoryPowerValues)
+	result = appendCategoryProblem(result, CategoryStationTag, l.Category.Station, categoryStationValues)
+	result = appendCategoryProblem(result, CategoryTimeTag, l.Category.Time, categoryTimeValues)
+	result = appendCategoryProblem(result, CategoryTransmitterTag, l.Category.Transmitter, categoryTransmitterValues)
+	result = appendCategoryProblem(result, CategoryOverlayTag, l.Category.Overlay, categoryOverlayValues)
+
+	operators := make(map[callsign.Callsign]bool, len(l.Operators))
+	for _, operator := range l.Operators {
+		if operators[operator] {
+			result = append(result, headerProblem(OperatorsTag, "%s is listed more than once", operator))
+		}
+		operators[operator] = true
+	}
+
+	if len(l.Address.Lines()) > MaxAddressLines {
+		result = append(result, headerProblem(AddressTag, "the address has %d lines, only %d lines are allowed", len(l.Address.Lines()), MaxAddressLines))
+	}
+
+	if !l.Offtime.Begin.IsZero() && l.Offtime.End.Before(l.Offtime.Begin) {
+		result = append(result, headerProblem(OfftimeTag, "the offtime ends before it begins"))
+	}
+
+	for i, qso := range l.QSOData {
+		result = append(result, validateQSO(i, qso)...)
+	}
+
+	return result
+}
+
+func appendCategoryProblem[T ~string](problems []Problem, tag Tag, value T, validValues []T) []Problem {
+	if value == "" || slices.Contains(validValues, value) {
+		return problems
+	}
+	return append(problems, headerProblem(tag, "%q is not a valid value", value))
+}
+
+func validateQSO(index int, qso QSO) []Problem {
+	var result []Problem
+	if !isValidQSOFrequency(qso.Frequency) {
+		result = append(result, qsoProblem(index, "%q is not a valid frequency", qso.Frequency))
+	}
+	if !slices.Contains(qsoModeValues, qso.Mode) {
+		result = append(result, qsoProblem(index, "%q is not a valid mode", qso.Mode))
+	}
+	if qso.Timestamp.IsZero() {
+		result = append(result, qsoProblem(index, "the timestamp is missing"))
+	}
+	if qso.Sent.Call.String() == "" {
+		result = append(result, qsoProblem(index, "the sent callsign is missing"))
+	}
+	if qso.Received.Call.String() == "" {
+		result = append(result, qsoProblem(index, "the received callsign is missing"))
+	}
+	if len(qso.Sent.Exchange) != len(qso.Received.Exchange) {
+		result = append(result, qsoProblem(index, "the sent and received exchange differ in length"))
+	}
+	return result
+}
+
+func isValidQSOFrequency(f QSOFrequency) bool {
+	if f.IsFrequency() {
+		return true
+	}
+	switch band := f.ToBand(); band {
+	case BandAll, BandVHF_3Band, BandVHF_FMOnly:
+		return false
+	default:
+		return slices.Contains(categoryBandValues, band) && f != ""
+	}
+}
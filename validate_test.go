@@ -0,0 +1,85 @@
+package cabrillo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/callsign"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	validQSO := QSO{
+		Frequency: "14025",
+		Mode:      QSOModeCW,
+		Timestamp: time.Date(2024, time.November, 23, 7, 11, 0, 0, time.UTC),
+		Sent:      QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}},
+		Received:  QSOInfo{Call: callsign.MustParse("W1AW"), Exchange: []string{"599", "5"}},
+	}
+	validLog := func() *Log {
+		result := NewLog()
+		result.Callsign = callsign.MustParse("DL1ABC")
+		result.Contest = "CQ-WW-CW"
+		result.Category.Band = BandAll
+		result.QSOData = []QSO{validQSO}
+		return result
+	}
+
+	tt := []struct {
+		desc     string
+		modify   func(*Log)
+		expected []Problem
+	}{
+		{
+			desc:   "valid",
+			modify: func(*Log) {},
+		},
+		{
+			desc:     "missing callsign",
+			modify:   func(l *Log) { l.Callsign = callsign.Callsign{} },
+			expected: []Problem{{Tag: CallsignTag, QSO: -1, Message: "the callsign is missing"}},
+		},
+		{
+			desc:     "invalid category value",
+			modify:   func(l *Log) { l.Category.Mode = "PH" },
+			expected: []Problem{{Tag: CategoryModeTag, QSO: -1, Message: `"PH" is not a valid value`}},
+		},
+		{
+			desc: "duplicate operator",
+			modify: func(l *Log) {
+				l.Operators = []callsign.Callsign{callsign.MustParse("DL1ABC"), callsign.MustParse("DL1ABC")}
+			},
+			expected: []Problem{{Tag: OperatorsTag, QSO: -1, Message: "DL1ABC is listed more than once"}},
+		},
+		{
+			desc:   "six address lines",
+			modify: func(l *Log) { l.Address.Text = "1\n2\n3\n4\n5\n6" },
+		},
+		{
+			desc:     "too many address lines",
+			modify:   func(l *Log) { l.Address.Text = "1\n2\n3\n4\n5\n6\n7" },
+			expected: []Problem{{Tag: AddressTag, QSO: -1, Message: "the address has 7 lines, only 6 lines are allowed"}},
+		},
+		{
+			desc:     "invalid frequency",
+			modify:   func(l *Log) { l.QSOData[0].Frequency = "14" },
+			expected: []Problem{{Tag: QSOTag, QSO: 0, Message: `"14" is not a valid frequency`}},
+		},
+		{
+			desc:   "VHF band as frequency",
+			modify: func(l *Log) { l.QSOData[0].Frequency = Frequency1_2GHz },
+		},
+		{
+			desc:     "invalid mode",
+			modify:   func(l *Log) { l.QSOData[0].Mode = "SSB" },
+			expected: []Problem{{Tag: QSOTag, QSO: 0, Message: `"SSB" is not a valid mode`}},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			log := validLog()
+			tc.modify(log)
+			assert.Equal(t, tc.expected, Validate(log))
+		})
+	}
+}
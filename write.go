@@ -1,43 +1,349 @@
 package cabrillo
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ftl/hamradio/callsign"
 )
 
+// defaultTagOrder is the order in which Write emits the header tags.
+var defaultTagOrder = []Tag{
+	CreatedByTag, ContestTag, CallsignTag, OperatorsTag, GridLocatorTag, LocationTag,
+	ClaimedScoreTag, OfftimeTag, CategoryAssistedTag, CategoryBandTag, CategoryModeTag,
+	CategoryOperatorTag, CategoryPowerTag, CategoryStationTag, CategoryTimeTag,
+	CategoryTransmitterTag, CategoryOverlayTag, CertificateTag, ClubTag, NameTag, EmailTag,
+	AddressTag, AddressCityTag, AddressStateProvinceTag, AddressPostalcodeTag, AddressCountryTag,
+	SoapboxTag,
+}
+
+// Write writes the given log with the default tags. The transmitter column is written if appendTX is true.
 func Write(w io.Writer, l *Log, appendTX bool) error {
-	tags := make([]Tag, 0, len(rowGenerators)+len(l.Custom))
-	tags = append(tags,
-		CreatedByTag, ContestTag, CallsignTag, OperatorsTag, GridLocatorTag, LocationTag,
-		ClaimedScoreTag, OfftimeTag, CategoryAssistedTag, CategoryBandTag, CategoryModeTag,
-		CategoryOperatorTag, CategoryPowerTag, CategoryStationTag, CategoryTimeTag,
-		CategoryTransmitterTag, CategoryOverlayTag, CertificateTag, ClubTag, NameTag, EmailTag,
-		AddressTag, AddressCityTag, AddressStateProvinceTag, AddressPostalcodeTag, AddressCountryTag,
-		SoapboxTag,
+	return NewWriter(WithTransmitterColumn(transmitterColumn(appendTX))).WriteLog(w, l)
+}
+
+// WriteContext writes the given log like Write. It stops if the given context is done and returns
+// the error of the context, wrapped with the line that was reached.
+func WriteContext(ctx context.Context, w io.Writer, l *Log, appendTX bool) error {
+	return NewWriter(WithTransmitterColumn(transmitterColumn(appendTX))).WriteLogContext(ctx, w, l)
+}
+
+// WriteWithTags writes the given log with the given tags in the given order.
+func WriteWithTags(w io.Writer, l *Log, appendTX bool, ommitIfEmpty bool, tags ...Tag) error {
+	writer := NewWriter(
+		WithTransmitterColumn(transmitterColumn(appendTX)),
+		WithOmitEmptyTags(ommitIfEmpty),
+		WithTagOrder(tags...),
 	)
-	for tag := range l.Custom {
-		tags = append(tags, tag)
+	return writer.WriteLog(w, l)
+}
+
+func transmitterColumn(appendTX bool) TransmitterColumn {
+	if appendTX {
+		return TransmitterAlways
 	}
+	return TransmitterNever
+}
+
+// Writer writes Cabrillo logs. It is configured through WriterOptions. Generators for additional tags
+// can be registered on a Writer without affecting any other Writer.
+type Writer struct {
+	transmitter   TransmitterColumn
+	ommitIfEmpty  bool
+	tags          []Tag
+	lineEnding    LineEnding
+	wrapWidth     int
+	alignQSOs     bool
+	sortQSOs      bool
+	redaction     *Redaction
+	transliterate bool
+	upperCase     bool
 
-	return WriteWithTags(w, l, appendTX, true, tags...)
+	rowGenerators map[Tag]rowGenerator
+	extensionTags []Tag
 }
 
-func WriteWithTags(w io.Writer, l *Log, appendTX bool, ommitIfEmpty bool, tags ...Tag) error {
+// WriterOption configures a Writer.
+type WriterOption func(*Writer)
+
+// NewWriter returns a new Writer with the given options. By default, the Writer omits empty tags, writes the
+// transmitter column only if needed (see NeedsTransmitterColumn), uses LF line endings and wraps long lines
+// after 75 characters.
+func NewWriter(options ...WriterOption) *Writer {
+	result := &Writer{
+		transmitter:   TransmitterAuto,
+		ommitIfEmpty:  true,
+		lineEnding:    LF,
+		wrapWidth:     maxLineLength,
+		rowGenerators: make(map[Tag]rowGenerator),
+	}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+// WithTransmitterColumn controls if the transmitter column is written.
+func WithTransmitterColumn(transmitter TransmitterColumn) WriterOption {
+	return func(w *Writer) {
+		w.transmitter = transmitter
+	}
+}
+
+// WithOmitEmptyTags controls if tags without a value are omitted.
+func WithOmitEmptyTags(omit bool) WriterOption {
+	return func(w *Writer) {
+		w.ommitIfEmpty = omit
+	}
+}
+
+// WithTagOrder writes only the given tags in the given order, the values of each custom tag are written at
+// the position of the tag. By default, the Writer writes the builtin tags, followed by the registered tags and
+// the custom tags of the log. Then the values of the custom tags are written in their original order.
+func WithTagOrder(tags ...Tag) WriterOption {
+	return func(w *Writer) {
+		w.tags = append([]Tag{}, tags...)
+	}
+}
+
+// WithLineEnding sets the line ending.
+func WithLineEnding(lineEnding LineEnding) WriterOption {
+	return func(w *Writer) {
+		w.lineEnding = lineEnding
+	}
+}
+
+// WithWrapWidth sets the maximum length of the soapbox and operators lines. Longer values are wrapped
+// into several lines. A width of 0 disables wrapping.
+func WithWrapWidth(width int) WriterOption {
+	return func(w *Writer) {
+		w.wrapWidth = width
+	}
+}
+
+// WithAlignedQSOs controls if the QSO columns are aligned.
+func WithAlignedQSOs(align bool) WriterOption {
+	return func(w *Writer) {
+		w.alignQSOs = align
+	}
+}
+
+// WithSortedQSOs controls if the QSOs are written in the order of CompareQSOs, regardless of their order in the log.
+func WithSortedQSOs(sort bool) WriterOption {
+	return func(w *Writer) {
+		w.sortQSOs = sort
+	}
+}
+
+// WithRedaction removes personal data from the written log, e.g. using PublicRedaction().
+func WithRedaction(redaction *Redaction) WriterOption {
+	return func(w *Writer) {
+		w.redaction = redaction
+	}
+}
+
+// WithTransliteration controls if all non-ASCII characters are replaced with ASCII equivalents, see Transliterate.
+func WithTransliteration(transliterate bool) WriterOption {
+	return func(w *Writer) {
+		w.transliterate = transliterate
+	}
+}
+
+// RegisterTag registers the generator for the given tag. The generator returns the values of all lines
+// with this tag, it takes precedence over the builtin handling of the tag. Write emits the registered tags
+// after the builtin tags in the order of their registration. Use Extension to retrieve the value from the log.
+func (w *Writer) RegisterTag(tag Tag, generate func(l *Log) []string) {
+	tag = Tag(strings.ToUpper(string(tag)))
+	if w.rowGenerators == nil {
+		w.rowGenerators = make(map[Tag]rowGenerator)
+	}
+	if _, found := w.rowGenerators[tag]; !found {
+		w.extensionTags = append(w.extensionTags, tag)
+	}
+	w.rowGenerators[tag] = rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		values := generate(l)
+		result := make([]row, 0, len(values))
+		for _, value := range values {
+			if config.transliterate {
+				value = Transliterate(value)
+			}
+			result = append(result, row{tag, value, config.ommitIfEmpty})
+		}
+		return result
+	})
+}
+
+// WriteLog writes the given log.
+func (w *Writer) WriteLog(out io.Writer, l *Log) error {
+	return w.WriteLogContext(context.Background(), out, l)
+}
+
+// WriteLogContext writes the given log. It stops if the given context is done and returns
+// the error of the context, wrapped with the line that was reached.
+func (w *Writer) WriteLogContext(ctx context.Context, out io.Writer, l *Log) error {
+	l, config, tags := w.prepare(l)
+	return writeLog(w.output(ctx, out), l, config, tags)
+}
+
+// prepare applies the redaction and the transliteration to the given log and resolves the configuration and
+// the tags for writing it.
+func (w *Writer) prepare(l *Log) (*Log, writeConfig, []Tag) {
+	if w.redaction != nil {
+		l = w.redaction.Redact(l)
+	}
+	if w.transliterate {
+		l = transliteratedLog(l)
+	}
+
+	var appendTX bool
+	switch w.transmitter {
+	case TransmitterAlways:
+		appendTX = true
+	case TransmitterNever:
+		appendTX = false
+	default:
+		appendTX = NeedsTransmitterColumn(l)
+	}
+	tags := w.tags
+	tagOrder := tags != nil
+	if !tagOrder {
+		tags = w.defaultTags(l)
+	}
+	config := writeConfig{
+		appendTX:      appendTX,
+		ommitIfEmpty:  w.ommitIfEmpty,
+		alignQSOs:     w.alignQSOs,
+		sortQSOs:      w.sortQSOs,
+		wrapWidth:     w.wrapWidth,
+		upperCase:     w.upperCase,
+		tagOrder:      tagOrder,
+		transliterate: w.transliterate,
+		extensions:    w.rowGenerators,
+	}
+	return l, config, tags
+}
+
+// output wraps the given writer to apply the line ending and the context.
+func (w *Writer) output(ctx context.Context, out io.Writer) io.Writer {
+	out = newLineEndingWriter(out, w.lineEnding)
+	return newContextWriter(ctx, out)
+}
+
+// Write writes the given log with the default tags, like the package function Write.
+// The transmitter option is overridden by appendTX.
+func (w *Writer) Write(out io.Writer, l *Log, appendTX bool) error {
+	return w.WriteContext(context.Background(), out, l, appendTX)
+}
+
+// WriteContext writes the given log like Write. It stops if the given context is done and returns
+// the error of the context, wrapped with the line that was reached.
+func (w *Writer) WriteContext(ctx context.Context, out io.Writer, l *Log, appendTX bool) error {
+	writer := *w
+	writer.transmitter = transmitterColumn(appendTX)
+	writer.tags = nil
+	return writer.WriteLogContext(ctx, out, l)
+}
+
+// WriteWithTags writes the given log with the given tags, like the package function WriteWithTags.
+// The transmitter, omit empty tags and tag order options are overridden by the parameters.
+func (w *Writer) WriteWithTags(out io.Writer, l *Log, appendTX bool, ommitIfEmpty bool, tags ...Tag) error {
+	return w.WriteWithTagsContext(context.Background(), out, l, appendTX, ommitIfEmpty, tags...)
+}
+
+// WriteWithTagsContext writes the given log like WriteWithTags. It stops if the given context is done and returns
+// the error of the context, wrapped with the line that was reached.
+func (w *Writer) WriteWithTagsContext(ctx context.Context, out io.Writer, l *Log, appendTX bool, ommitIfEmpty bool, tags ...Tag) error {
+	writer := *w
+	writer.transmitter = transmitterColumn(appendTX)
+	writer.ommitIfEmpty = ommitIfEmpty
+	WithTagOrder(tags...)(&writer)
+	return writer.WriteLogContext(ctx, out, l)
+}
+
+// defaultTags returns the builtin tags, followed by the registered tags and the custom tags of the given log.
+func (w *Writer) defaultTags(l *Log) []Tag {
+	result := make([]Tag, 0, len(defaultTagOrder)+len(w.extensionTags)+len(l.Custom))
+	result = append(result, defaultTagOrder...)
+	for _, tag := range w.extensionTags {
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	for _, tag := range l.Custom.Tags() {
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// writeConfig controls how a log is written.
+type writeConfig struct {
+	appendTX      bool
+	ommitIfEmpty  bool
+	alignQSOs     bool
+	sortQSOs      bool
+	wrapWidth     int
+	upperCase     bool
+	tagOrder      bool
+	transliterate bool
+	extensions    map[Tag]rowGenerator
+}
+
+// callsign returns the given callsign as it is written into the log.
+func (c writeConfig) callsign(call callsign.Callsign) string {
+	if c.upperCase {
+		return formatCallsign(call)
+	}
+	return call.String()
+}
+
+func writeLog(w io.Writer, l *Log, config writeConfig, tags []Tag) error {
+	err := writeHeader(w, l, config, tags)
+	if err != nil {
+		return err
+	}
+
+	err = writeQSOData(w, l, config)
+	if err != nil {
+		return err
+	}
+
+	err = writeRows(w, row{EndOfLogTag, "", false})
+	return err
+}
+
+// writeHeader writes the START-OF-LOG line and the given tags.
+func writeHeader(w io.Writer, l *Log, config writeConfig, tags []Tag) error {
 	err := writeRows(w, row{StartOfLogTag, l.CabrilloVersion, false})
 	if err != nil {
 		return err
 	}
 
+	customWritten := false
 	for _, tag := range tags {
-		generator, ok := rowGenerators[tag]
+		generator, ok := config.extensions[tag]
+		if !ok {
+			generator, ok = rowGenerators[tag]
+		}
 		var rows []row
-		if ok {
-			rows = generator.ToRow(l, ommitIfEmpty)
-		} else {
-			rows = []row{{tag, l.Custom[tag], ommitIfEmpty}}
+		switch {
+		case ok:
+			rows = generator.ToRow(l, config)
+		case len(l.Custom.Values(tag)) == 0:
+			rows = customRows(tag, nil, config.ommitIfEmpty)
+		case config.tagOrder:
+			rows = customRows(tag, l.Custom.Values(tag), config.ommitIfEmpty)
+		case !customWritten:
+			// without a given tag order, the values of all custom tags are written at the position of the first one,
+			// in their original order
+			rows = customValueRows(l.Custom, tags, config)
+			customWritten = true
 		}
 
 		if rows == nil {
@@ -49,101 +355,107 @@ func WriteWithTags(w io.Writer, l *Log, appendTX bool, ommitIfEmpty bool, tags .
 			return err
 		}
 	}
+	return nil
+}
 
-	err = writeQSOs(w, QSOTag, l.QSOData, appendTX)
-	if err != nil {
-		return err
+// writeQSOData writes the QSO and X-QSO lines.
+func writeQSOData(w io.Writer, l *Log, config writeConfig) error {
+	qsos, ignoredQSOs := l.QSOData, l.IgnoredQSOs
+	if config.sortQSOs {
+		qsos, ignoredQSOs = sortedQSOs(qsos), sortedQSOs(ignoredQSOs)
+	}
+
+	var columnWidths [][]int
+	if config.alignQSOs {
+		columnWidths = qsoColumnWidths(config, qsos, ignoredQSOs)
 	}
 
-	err = writeQSOs(w, XQSOTag, l.IgnoredQSOs, appendTX)
+	err := writeQSOs(w, QSOTag, qsos, config, columnWidths)
 	if err != nil {
 		return err
 	}
 
-	err = writeRows(w, row{EndOfLogTag, "", false})
-	return err
+	return writeQSOs(w, XQSOTag, ignoredQSOs, config, columnWidths)
 }
 
 type rowGenerator interface {
-	ToRow(*Log, bool) []row
+	ToRow(*Log, writeConfig) []row
 }
 
-type rowGeneratorFunc func(*Log, bool) []row
+type rowGeneratorFunc func(*Log, writeConfig) []row
 
-func (f rowGeneratorFunc) ToRow(l *Log, ommitIfEmpty bool) []row {
-	return f(l, ommitIfEmpty)
+func (f rowGeneratorFunc) ToRow(l *Log, config writeConfig) []row {
+	return f(l, config)
 }
 
 var rowGenerators = map[Tag]rowGenerator{
-	CallsignTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CallsignTag, l.Callsign.String(), ommitIfEmpty}}
+	CallsignTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CallsignTag, config.callsign(l.Callsign), config.ommitIfEmpty}}
 	}),
-	ContestTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{ContestTag, string(l.Contest), ommitIfEmpty}}
+	ContestTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{ContestTag, string(l.Contest), config.ommitIfEmpty}}
 	}),
-	CategoryAssistedTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryAssistedTag, string(l.Category.Assisted), ommitIfEmpty}}
+	CategoryAssistedTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryAssistedTag, string(l.Category.Assisted), config.ommitIfEmpty}}
 	}),
-	CategoryBandTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryBandTag, string(l.Category.Band), ommitIfEmpty}}
+	CategoryBandTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryBandTag, string(l.Category.Band), config.ommitIfEmpty}}
 	}),
-	CategoryModeTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryModeTag, string(l.Category.Mode), ommitIfEmpty}}
+	CategoryModeTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryModeTag, string(l.Category.Mode), config.ommitIfEmpty}}
 	}),
-	CategoryOperatorTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryOperatorTag, string(l.Category.Operator), ommitIfEmpty}}
+	CategoryOperatorTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryOperatorTag, string(l.Category.Operator), config.ommitIfEmpty}}
 	}),
-	CategoryPowerTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryPowerTag, string(l.Category.Power), ommitIfEmpty}}
+	CategoryPowerTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryPowerTag, string(l.Category.Power), config.ommitIfEmpty}}
 	}),
-	CategoryStationTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryStationTag, string(l.Category.Station), ommitIfEmpty}}
+	CategoryStationTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryStationTag, string(l.Category.Station), config.ommitIfEmpty}}
 	}),
-	CategoryTimeTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryTimeTag, string(l.Category.Time), ommitIfEmpty}}
+	CategoryTimeTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryTimeTag, string(l.Category.Time), config.ommitIfEmpty}}
 	}),
-	CategoryTransmitterTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryTransmitterTag, string(l.Category.Transmitter), ommitIfEmpty}}
+	CategoryTransmitterTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryTransmitterTag, string(l.Category.Transmitter), config.ommitIfEmpty}}
 	}),
-	CategoryOverlayTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CategoryOverlayTag, string(l.Category.Overlay), ommitIfEmpty}}
+	CategoryOverlayTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CategoryOverlayTag, string(l.Category.Overlay), config.ommitIfEmpty}}
 	}),
 	CertificateTag: rowGeneratorFunc(certificateRow),
-	ClaimedScoreTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{ClaimedScoreTag, strconv.Itoa(l.ClaimedScore), ommitIfEmpty}}
+	ClaimedScoreTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{ClaimedScoreTag, strconv.Itoa(l.ClaimedScore), config.ommitIfEmpty}}
 	}),
-	ClubTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{ClubTag, l.Club, ommitIfEmpty}}
+	ClubTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{ClubTag, l.Club, config.ommitIfEmpty}}
 	}),
-	CreatedByTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{CreatedByTag, l.CreatedBy, ommitIfEmpty}}
+	CreatedByTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{CreatedByTag, l.CreatedBy, config.ommitIfEmpty}}
 	}),
-	EmailTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{EmailTag, l.Email, ommitIfEmpty}}
+	EmailTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{EmailTag, l.Email, config.ommitIfEmpty}}
 	}),
-	GridLocatorTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{GridLocatorTag, l.GridLocator.String(), ommitIfEmpty}}
+	GridLocatorTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{GridLocatorTag, l.GridLocator.String(), config.ommitIfEmpty}}
 	}),
-	LocationTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{LocationTag, l.Location, ommitIfEmpty}}
+	LocationTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{LocationTag, l.Location, config.ommitIfEmpty}}
 	}),
-	NameTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{NameTag, l.Name, ommitIfEmpty}}
+	NameTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{NameTag, l.Name, config.ommitIfEmpty}}
 	}),
-	AddressTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{AddressTag, l.Address.Text, ommitIfEmpty}}
+	AddressTag: rowGeneratorFunc(addressRows),
+	AddressCityTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{AddressCityTag, l.Address.City, config.ommitIfEmpty}}
 	}),
-	AddressCityTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{AddressCityTag, l.Address.City, ommitIfEmpty}}
+	AddressStateProvinceTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{AddressStateProvinceTag, l.Address.StateProvince, config.ommitIfEmpty}}
 	}),
-	AddressStateProvinceTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{AddressStateProvinceTag, l.Address.StateProvince, ommitIfEmpty}}
+	AddressPostalcodeTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{AddressPostalcodeTag, l.Address.Postalcode, config.ommitIfEmpty}}
 	}),
-	AddressPostalcodeTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{AddressPostalcodeTag, l.Address.Postalcode, ommitIfEmpty}}
-	}),
-	AddressCountryTag: rowGeneratorFunc(func(l *Log, ommitIfEmpty bool) []row {
-		return []row{{AddressCountryTag, l.Address.Country, ommitIfEmpty}}
+	AddressCountryTag: rowGeneratorFunc(func(l *Log, config writeConfig) []row {
+		return []row{{AddressCountryTag, l.Address.Country, config.ommitIfEmpty}}
 	}),
 	OperatorsTag: rowGeneratorFunc(operatorsRow),
 	OfftimeTag:   rowGeneratorFunc(offtimeRow),
@@ -178,31 +490,91 @@ func writeRows(w io.Writer, rows ...row) error {
 	return nil
 }
 
-func certificateRow(l *Log, ommitIfEmpty bool) []row {
+// customRows writes each custom value into a separate row. Values that contain
+// line breaks are split into several rows.
+func customRows(tag Tag, values []string, ommitIfEmpty bool) []row {
+	if len(values) == 0 {
+		return []row{{tag, "", ommitIfEmpty}}
+	}
+	result := make([]row, 0, len(values))
+	for _, value := range values {
+		for _, line := range strings.Split(value, "\n") {
+			result = append(result, row{tag, line, ommitIfEmpty})
+		}
+	}
+	return result
+}
+
+// customValueRows writes the custom values of the given tags in the order in which they are stored in the log.
+// Tags that are handled by a generator are skipped.
+func customValueRows(custom CustomTags, tags []Tag, config writeConfig) []row {
+	result := make([]row, 0, len(custom))
+	for _, value := range custom {
+		if !slices.Contains(tags, value.Tag) || config.extensions[value.Tag] != nil || rowGenerators[value.Tag] != nil {
+			continue
+		}
+		result = append(result, customRows(value.Tag, []string{value.Value}, config.ommitIfEmpty)...)
+	}
+	return result
+}
+
+func certificateRow(l *Log, config writeConfig) []row {
 	value := "YES"
 	if !l.Certificate {
 		value = "NO"
 	}
-	return []row{{CertificateTag, value, ommitIfEmpty}}
+	return []row{{CertificateTag, value, config.ommitIfEmpty}}
 }
 
-func operatorsRow(l *Log, ommitIfEmpty bool) []row {
+func operatorsRow(l *Log, config writeConfig) []row {
 	operators := make([]string, 0, len(l.Operators)+1)
 	if l.Host.String() != "" {
-		operators = append(operators, "@"+l.Host.String())
+		operators = append(operators, "@"+config.callsign(l.Host))
 	}
 	for _, op := range l.Operators {
 		if op == l.Host {
 			continue
 		}
-		operators = append(operators, op.String())
+		operators = append(operators, config.callsign(op))
 	}
 
-	value := strings.Join(operators, ", ")
-	return wrapRows(OperatorsTag, value, ommitIfEmpty)
+	return listRows(OperatorsTag, operators, ", ", config.wrapWidth, config.ommitIfEmpty)
 }
 
-func offtimeRow(l *Log, ommitIfEmpty bool) []row {
+// listRows joins the given items into as few rows as possible. An item is never split across rows.
+// A width of 0 joins all items into one row.
+func listRows(tag Tag, items []string, separator string, width int, ommitIfEmpty bool) []row {
+	maxValueLength := width - len(tag) - 2 // tag + colon + space
+	var result []row
+	var value string
+	for _, item := range items {
+		switch {
+		case value == "":
+			value = item
+		case width <= 0 || len(value)+len(separator)+len(item) <= maxValueLength:
+			value += separator + item
+		default:
+			result = append(result, row{tag, value, ommitIfEmpty})
+			value = item
+		}
+	}
+	result = append(result, row{tag, value, ommitIfEmpty})
+	return result
+}
+
+func addressRows(l *Log, config writeConfig) []row {
+	lines := l.Address.Lines()
+	if len(lines) == 0 {
+		return []row{{AddressTag, "", config.ommitIfEmpty}}
+	}
+	result := make([]row, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, row{AddressTag, line, config.ommitIfEmpty})
+	}
+	return result
+}
+
+func offtimeRow(l *Log, config writeConfig) []row {
 	var value string
 	if l.Offtime.Begin.IsZero() || l.Offtime.End.IsZero() {
 		value = ""
@@ -212,25 +584,37 @@ func offtimeRow(l *Log, ommitIfEmpty bool) []row {
 			formatTimestamp(l.Offtime.End),
 		)
 	}
-	return []row{{OfftimeTag, value, ommitIfEmpty}}
+	return []row{{OfftimeTag, value, config.ommitIfEmpty}}
 }
 
 func formatTimestamp(timestamp time.Time) string {
 	return timestamp.UTC().Format(TimestampLayout)
 }
 
-func soapboxRows(l *Log, ommitIfEmpty bool) []row {
+// formatCallsign returns the callsign in uppercase, for comparisons and reports.
+func formatCallsign(call callsign.Callsign) string {
+	return strings.ToUpper(call.String())
+}
+
+func soapboxRows(l *Log, config writeConfig) []row {
 	lines := strings.Split(l.Soapbox, "\n")
 	result := make([]row, 0, len(lines))
 	for _, line := range lines {
-		result = append(result, wrapRows(SoapboxTag, line, ommitIfEmpty)...)
+		result = append(result, wrapRows(SoapboxTag, line, config.wrapWidth, config.ommitIfEmpty)...)
 	}
 	return result
 }
 
-func wrapRows(tag Tag, value string, ommitIfEmpty bool) []row {
-	const maxLength = 75
-	maxValueLength := maxLength - len(tag) - 2 // tag + colon + space
+// maxLineLength is the default maximum length of a header line.
+const maxLineLength = 75
+
+// wrapRows splits the given value into rows of the given width, preferably at whitespace.
+// A width of 0 disables wrapping.
+func wrapRows(tag Tag, value string, width int, ommitIfEmpty bool) []row {
+	if width <= 0 {
+		return []row{{tag, value, ommitIfEmpty}}
+	}
+	maxValueLength := max(width-len(tag)-2, 1) // tag + colon + space
 	result := make([]row, 0, (len(value)/maxValueLength)+1)
 	for len(value) > maxValueLength {
 		wrapIndex := strings.LastIndexAny(value[:maxValueLength], " \n\t")
@@ -246,9 +630,9 @@ func wrapRows(tag Tag, value string, ommitIfEmpty bool) []row {
 	return result
 }
 
-func writeQSOs(w io.Writer, tag Tag, data []QSO, appendTX bool) error {
+func writeQSOs(w io.Writer, tag Tag, data []QSO, config writeConfig, columnWidths [][]int) error {
 	for _, qso := range data {
-		err := writeQSO(w, tag, qso, appendTX)
+		err := writeQSO(w, tag, qso, config, columnWidths)
 		if err != nil {
 			return err
 		}
@@ -256,26 +640,71 @@ func writeQSOs(w io.Writer, tag Tag, data []QSO, appendTX bool) error {
 	return nil
 }
 
-func writeQSO(w io.Writer, tag Tag, data QSO, appendTX bool) error {
-	_, err := fmt.Fprintf(w, "%s: %s %s %s %s %s %s %s",
-		tag,
-		data.Frequency,
-		data.Mode,
-		formatTimestamp(data.Timestamp),
-		data.Sent.Call.String(),
-		strings.Join(data.Sent.Exchange, " "),
-		data.Received.Call.String(),
-		strings.Join(data.Received.Exchange, " "),
-	)
-	if err != nil {
-		return err
+// qsoColumnGroups returns the columns of the QSO line in groups that are aligned separately: frequency, mode,
+// timestamp and sent call; the sent exchange; the received call and exchange; the transmitter.
+func qsoColumnGroups(data QSO, config writeConfig) [][]string {
+	result := [][]string{
+		{
+			string(data.Frequency),
+			string(data.Mode),
+			formatTimestamp(data.Timestamp),
+			config.callsign(data.Sent.Call),
+		},
+		data.Sent.Exchange,
+		append([]string{config.callsign(data.Received.Call)}, data.Received.Exchange...),
 	}
-	if appendTX {
-		_, err = fmt.Fprintf(w, " %d", data.Transmitter)
-		if err != nil {
-			return err
+	if config.appendTX {
+		result = append(result, []string{strconv.Itoa(data.Transmitter)})
+	}
+	return result
+}
+
+// qsoColumnWidths returns the maximum width of each QSO column within its group. If the exchanges of the
+// QSOs differ in length, the shorter exchanges are padded, so that the received call is always aligned.
+func qsoColumnWidths(config writeConfig, data ...[]QSO) [][]int {
+	var result [][]int
+	for _, qsos := range data {
+		for _, qso := range qsos {
+			for i, group := range qsoColumnGroups(qso, config) {
+				if i == len(result) {
+					result = append(result, nil)
+				}
+				for j, column := range group {
+					if j == len(result[i]) {
+						result[i] = append(result[i], 0)
+					}
+					result[i][j] = max(result[i][j], len(column))
+				}
+			}
+		}
+	}
+	return result
+}
+
+func writeQSO(w io.Writer, tag Tag, data QSO, config writeConfig, columnWidths [][]int) error {
+	groups := qsoColumnGroups(data, config)
+	var line string
+	if columnWidths == nil {
+		line = strings.Join(slices.Concat(groups...), " ")
+	} else {
+		columns := make([]string, 0, len(groups[0])+len(groups[1])+len(groups[2])+1)
+		for i, widths := range columnWidths {
+			for j, width := range widths {
+				var column string
+				if j < len(groups[i]) {
+					column = groups[i][j]
+				}
+				padding := strings.Repeat(" ", width-len(column))
+				if i == 0 && j == 0 {
+					// the frequency is aligned to the right
+					columns = append(columns, padding+column)
+				} else {
+					columns = append(columns, column+padding)
+				}
+			}
 		}
+		line = strings.TrimRight(strings.Join(columns, " "), " ")
 	}
-	_, err = fmt.Fprintln(w)
+	_, err := fmt.Fprintf(w, "%s: %s\n", tag, line)
 	return err
 }
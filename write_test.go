@@ -1,9 +1,15 @@
 package cabrillo
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ftl/hamradio/callsign"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,11 +26,9 @@ func TestTestdataRoundtrip(t *testing.T) {
 
 			originalLog, err := Read(originalFile)
 			require.NoError(t, err)
-			for tag, value := range originalLog.Custom {
-				if value == "" {
-					delete(originalLog.Custom, tag)
-				}
-			}
+			originalLog.Custom = slices.DeleteFunc(originalLog.Custom, func(value CustomValue) bool {
+				return value.Value == ""
+			})
 
 			outputFile, err := os.CreateTemp("", "cabrillo-roundtrip-"+entry.Name()+"-*")
 			assert.NoError(t, err)
@@ -45,6 +49,55 @@ func TestTestdataRoundtrip(t *testing.T) {
 	}
 }
 
+func TestWrite_CustomTagsInOriginalOrder(t *testing.T) {
+	input := "START-OF-LOG: 3.0\nX-ZULU: z\nX-ALPHA: a1\nX-MIKE: m\nX-ALPHA: a2\nEND-OF-LOG:\n"
+	log, err := Read(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, log.Custom.Values("X-ALPHA"))
+
+	for range 10 {
+		buffer := &bytes.Buffer{}
+		err = Write(buffer, log, false)
+		require.NoError(t, err)
+		assert.Equal(t, "START-OF-LOG: 3.0\nCLAIMED-SCORE: 0\nCERTIFICATE: NO\nX-ZULU: z\nX-ALPHA: a1\nX-MIKE: m\nX-ALPHA: a2\nEND-OF-LOG:\n", buffer.String())
+	}
+}
+
+func TestCustomTags_Set(t *testing.T) {
+	custom := CustomTags{{"X-A", "a1"}, {"X-B", "b"}, {"X-A", "a2"}}
+
+	custom.Set("X-A", "new")
+	assert.Equal(t, CustomTags{{"X-A", "new"}, {"X-B", "b"}}, custom)
+
+	custom.Set("X-C", "c1", "c2")
+	assert.Equal(t, CustomTags{{"X-A", "new"}, {"X-B", "b"}, {"X-C", "c1"}, {"X-C", "c2"}}, custom)
+}
+
+func TestOperatorsRow(t *testing.T) {
+	log := NewLog()
+	log.Host = callsign.MustParse("DL0ABC")
+	for i := range 12 {
+		log.Operators = append(log.Operators, callsign.MustParse(fmt.Sprintf("DL%dABCD", i)))
+	}
+
+	rows := operatorsRow(log, writeConfig{ommitIfEmpty: true, wrapWidth: maxLineLength})
+
+	require.Len(t, rows, 2)
+	var operators []string
+	for _, row := range rows {
+		line := row.String()
+		assert.True(t, len(line) <= 75, "%s = %d", line, len(line))
+		assert.False(t, strings.HasSuffix(line, ","), line)
+		operators = append(operators, operatorsSeparator.Split(row.value, -1)...)
+	}
+	assert.Equal(t, "@DL0ABC", operators[0])
+	assert.Len(t, operators, 13)
+	for _, operator := range operators {
+		_, err := callsign.Parse(strings.TrimPrefix(operator, "@"))
+		assert.NoError(t, err, operator)
+	}
+}
+
 func TestWrapRows(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -86,7 +139,7 @@ func TestWrapRows(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			actual := wrapRows(tt.tag, tt.value, true)
+			actual := wrapRows(tt.tag, tt.value, maxLineLength, true)
 			require.Equal(t, len(tt.expected), len(actual))
 			for i, row := range actual {
 				line := row.String()
@@ -96,3 +149,77 @@ func TestWrapRows(t *testing.T) {
 		})
 	}
 }
+
+func TestWriter_Options(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Callsign = callsign.MustParse("DL1ABC")
+	log.Soapbox = "123456789 123456789 123456789 123456789 123456789"
+	log.QSOData = []QSO{
+		{Frequency: "7025", Mode: QSOModeCW, Timestamp: time.Date(2024, time.October, 26, 0, 1, 0, 0, time.UTC), Sent: QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}}, Received: QSOInfo{Call: callsign.MustParse("W1AW"), Exchange: []string{"599", "5"}}, Transmitter: 1},
+		{Frequency: "14025", Mode: QSOModeCW, Timestamp: time.Date(2024, time.October, 26, 0, 0, 0, 0, time.UTC), Sent: QSOInfo{Call: callsign.MustParse("DL1ABC"), Exchange: []string{"599", "14"}}, Received: QSOInfo{Call: callsign.MustParse("K1AR"), Exchange: []string{"599", "5"}}},
+	}
+	tests := []struct {
+		name     string
+		options  []WriterOption
+		expected string
+	}{
+		{
+			name:     "defaults",
+			options:  []WriterOption{WithTagOrder(CallsignTag, SoapboxTag)},
+			expected: "START-OF-LOG: 3.0\nCALLSIGN: DL1ABC\nSOAPBOX: 123456789 123456789 123456789 123456789 123456789\nQSO: 7025 CW 2024-10-26 0001 DL1ABC 599 14 W1AW 599 5 1\nQSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 K1AR 599 5 0\nEND-OF-LOG:\n",
+		},
+		{
+			name:     "tag order and empty tags",
+			options:  []WriterOption{WithTagOrder(NameTag, CallsignTag), WithOmitEmptyTags(false), WithTransmitterColumn(TransmitterNever)},
+			expected: "START-OF-LOG: 3.0\nNAME:\nCALLSIGN: DL1ABC\nQSO: 7025 CW 2024-10-26 0001 DL1ABC 599 14 W1AW 599 5\nQSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 K1AR 599 5\nEND-OF-LOG:\n",
+		},
+		{
+			name:     "wrap width, sorted and aligned QSOs, CRLF",
+			options:  []WriterOption{WithTagOrder(SoapboxTag), WithWrapWidth(40), WithSortedQSOs(true), WithAlignedQSOs(true), WithLineEnding(CRLF)},
+			expected: "START-OF-LOG: 3.0\r\nSOAPBOX: 123456789 123456789 123456789\r\nSOAPBOX: 123456789 123456789\r\nQSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 K1AR 599 5 0\r\nQSO:  7025 CW 2024-10-26 0001 DL1ABC 599 14 W1AW 599 5 1\r\nEND-OF-LOG:\r\n",
+		},
+		{
+			name:     "no wrapping",
+			options:  []WriterOption{WithTagOrder(SoapboxTag), WithWrapWidth(0), WithTransmitterColumn(TransmitterNever)},
+			expected: "START-OF-LOG: 3.0\nSOAPBOX: 123456789 123456789 123456789 123456789 123456789\nQSO: 7025 CW 2024-10-26 0001 DL1ABC 599 14 W1AW 599 5\nQSO: 14025 CW 2024-10-26 0000 DL1ABC 599 14 K1AR 599 5\nEND-OF-LOG:\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+
+			err := NewWriter(tt.options...).WriteLog(buffer, log)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, buffer.String())
+		})
+	}
+}
+
+func TestWriter_WriteOverridesOptions(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Callsign = callsign.MustParse("DL1ABC")
+	writer := NewWriter(WithTagOrder(NameTag), WithOmitEmptyTags(false), WithLineEnding(CRLF))
+	buffer := &bytes.Buffer{}
+
+	err := writer.WriteWithTags(buffer, log, false, true, CallsignTag)
+	require.NoError(t, err)
+
+	assert.Equal(t, "START-OF-LOG: 3.0\r\nCALLSIGN: DL1ABC\r\nEND-OF-LOG:\r\n", buffer.String())
+}
+
+func TestWrite_CustomTagsWithTagOrder(t *testing.T) {
+	log := NewLog()
+	log.CabrilloVersion = "3.0"
+	log.Callsign = callsign.MustParse("DL1ABC")
+	log.Custom = CustomTags{{"X-ZULU", "z"}, {"X-ALPHA", "a1"}, {"X-MIKE", "m"}, {"X-ALPHA", "a2"}}
+	buffer := &bytes.Buffer{}
+
+	err := WriteWithTags(buffer, log, false, false, "X-ALPHA", CallsignTag, "X-ZULU", "X-EMPTY")
+	require.NoError(t, err)
+
+	assert.Equal(t, "START-OF-LOG: 3.0\nX-ALPHA: a1\nX-ALPHA: a2\nCALLSIGN: DL1ABC\nX-ZULU: z\nX-EMPTY:\nEND-OF-LOG:\n", buffer.String())
+}